@@ -0,0 +1,46 @@
+package main
+
+import "strings"
+
+// accessibilityIssues lists what keeps the document from being accessible to assistive
+// technology: the structural requirements of PDF/UA that can be checked without reading the
+// content. An empty list means no gaps were found.
+func accessibilityIssues(info *PDFInfo) []string {
+	var issues []string
+	if !info.IsTagged {
+		issues = append(issues, "not marked as tagged (/MarkInfo /Marked)")
+	}
+	if !info.HasStructTree {
+		issues = append(issues, "no structure tree (/StructTreeRoot)")
+	}
+	if info.DeclaredLanguage == "" {
+		issues = append(issues, "no document language (/Lang)")
+	}
+	if !info.DisplayDocTitle {
+		issues = append(issues, "the title bar shows the file name, not the title (DisplayDocTitle not set)")
+	}
+	if len(info.UnmappedStructureTypes) > 0 {
+		issues = append(issues, "custom structure types without a standard mapping: "+strings.Join(info.UnmappedStructureTypes, ", "))
+	}
+	return issues
+}
+
+// pdfUAConformance describes the PDF/UA claim of the XMP pdfuaid:part property: "PDF/UA-1"
+// when the structural checks pass, marked non-conformant when they don't, and "" when the
+// document does not claim PDF/UA
+func pdfUAConformance(part string, issues []string) string {
+	part = strings.TrimSpace(part)
+	if part == "" {
+		return ""
+	}
+	if len(issues) > 0 {
+		return "PDF/UA-" + part + " claimed, non-conformant"
+	}
+	return "PDF/UA-" + part
+}
+
+// analyzeAccessibility checks the structural accessibility requirements and the PDF/UA claim
+func (pa *PDFAnalyzer) analyzeAccessibility(info *PDFInfo) {
+	info.AccessibilityIssues = accessibilityIssues(info)
+	info.PDFUAConformance = pdfUAConformance(info.XMPProperties["pdfuaid:part"], info.AccessibilityIssues)
+}
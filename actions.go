@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// externalActionTypes are the action types that reach outside the document: they run programs,
+// open URLs or other files, or send data out
+var externalActionTypes = map[string]bool{
+	"Launch":     true,
+	"URI":        true,
+	"SubmitForm": true,
+	"ImportData": true,
+	"GoToR":      true,
+	"GoToE":      true,
+}
+
+// forEachAction calls fn for every action the document can trigger: the open action, the
+// document, page and annotation additional actions (/AA), annotation and link actions (/A) and
+// form field actions, including the actions chained by /Next. The location names where the
+// action was found, e.g. "OpenAction", "Page 2 /AA /O" or "Field: email /AA /K".
+func (pa *PDFAnalyzer) forEachAction(ctx *model.Context, fn func(location string, action types.Dict)) {
+	if ctx == nil || ctx.RootDict == nil {
+		return
+	}
+	var visit func(location string, obj types.Object, depth int)
+	visit = func(location string, obj types.Object, depth int) {
+		if obj == nil || depth > 8 {
+			return
+		}
+		resolved, err := ctx.Dereference(obj)
+		if err != nil || resolved == nil {
+			return
+		}
+		// /Next pode ser uma ação ou uma lista de ações
+		if arr, ok := resolved.(types.Array); ok {
+			for _, o := range arr {
+				visit(location, o, depth+1)
+			}
+			return
+		}
+		action, ok := resolved.(types.Dict)
+		if !ok {
+			return
+		}
+		fn(location, action)
+		if next, found := action.Find("Next"); found {
+			visit(location, next, depth+1)
+		}
+	}
+	visitAA := func(location string, d types.Dict) {
+		aaObj, found := d.Find("AA")
+		if !found || aaObj == nil {
+			return
+		}
+		aa, err := ctx.DereferenceDict(aaObj)
+		if err != nil || aa == nil {
+			return
+		}
+		triggers := make([]string, 0, len(aa))
+		for trigger := range aa {
+			triggers = append(triggers, trigger)
+		}
+		sort.Strings(triggers)
+		for _, trigger := range triggers {
+			visit(location+" /AA /"+trigger, aa[trigger], 0)
+		}
+	}
+
+	// /OpenAction também pode ser só um destino (array), sem ação
+	if openAction, err := ctx.DereferenceDict(ctx.RootDict["OpenAction"]); err == nil && openAction != nil {
+		visit("OpenAction", openAction, 0)
+	}
+	visitAA("Document", ctx.RootDict)
+
+	hasAcroForm := pa.acroFormDict(ctx) != nil
+	for i := 1; i <= ctx.PageCount; i++ {
+		pageDict, _, _, err := ctx.PageDict(i, false)
+		if err != nil || pageDict == nil {
+			continue
+		}
+		visitAA(fmt.Sprintf("Page %d", i), pageDict)
+
+		for _, annot := range pa.pageAnnotations(ctx, pageDict) {
+			location := fmt.Sprintf("Page %d annotation", i)
+			if isFormWidget(annot, hasAcroForm) {
+				location = "Field: " + pa.widgetFieldName(ctx, annot)
+				// As ações de campo podem estar no campo pai do widget
+				if parentObj, found := annot.Find("Parent"); found && parentObj != nil {
+					if parent, err := ctx.DereferenceDict(parentObj); err == nil && parent != nil {
+						visitAA(location, parent)
+					}
+				}
+			}
+			if action, found := annot.Find("A"); found {
+				visit(location, action, 0)
+			}
+			visitAA(location, annot)
+		}
+	}
+}
+
+// widgetFieldName returns the name of the form field a widget belongs to
+func (pa *PDFAnalyzer) widgetFieldName(ctx *model.Context, annot types.Dict) string {
+	if name := getStringFromDict(annot, "T"); name != "" {
+		return name
+	}
+	if parentObj, found := annot.Find("Parent"); found && parentObj != nil {
+		if parent, err := ctx.DereferenceDict(parentObj); err == nil && parent != nil {
+			return getStringFromDict(parent, "T")
+		}
+	}
+	return ""
+}
+
+// fileSpecTarget returns the file or URL a file specification points to: the string itself, or
+// the /UF or /F entry of a file specification dictionary
+func (pa *PDFAnalyzer) fileSpecTarget(ctx *model.Context, obj types.Object) string {
+	resolved, err := ctx.Dereference(obj)
+	if err != nil || resolved == nil {
+		return ""
+	}
+	spec, ok := resolved.(types.Dict)
+	if !ok {
+		return getStringFromDict(types.Dict{"f": resolved}, "f")
+	}
+	for _, key := range []string{"UF", "F"} {
+		if name := getStringFromDict(spec, key); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// actionTarget returns what an external action points to: the URI, or the launched, opened or
+// submitted-to file. Launch actions may name the program only in /Win, with its parameters.
+func (pa *PDFAnalyzer) actionTarget(ctx *model.Context, action types.Dict, actionType string) string {
+	if actionType == "URI" {
+		return getStringFromDict(action, "URI")
+	}
+	if obj, found := action.Find("F"); found && obj != nil {
+		if target := pa.fileSpecTarget(ctx, obj); target != "" {
+			return target
+		}
+	}
+	// /Launch para Windows: /Win << /F (programa) /P (parâmetros) >>
+	if winObj, found := action.Find("Win"); found && winObj != nil {
+		if win, err := ctx.DereferenceDict(winObj); err == nil && win != nil {
+			target := getStringFromDict(win, "F")
+			if params := getStringFromDict(win, "P"); params != "" {
+				target += " " + params
+			}
+			return target
+		}
+	}
+	return ""
+}
+
+// analyzeActions collects the actions that reach outside the document (launching programs,
+// opening URLs or remote files, submitting forms) with where they are and what they target. It
+// also notes whether an action other than going to a page runs when the document is opened.
+func (pa *PDFAnalyzer) analyzeActions(ctx *model.Context, info *PDFInfo) {
+	seen := make(map[ActionInfo]bool)
+	pa.forEachAction(ctx, func(location string, action types.Dict) {
+		s := action.NameEntry("S")
+		// Abrir numa página do próprio documento é inofensivo
+		if location == "OpenAction" && s != nil && *s != "GoTo" {
+			info.HasOpenAction = true
+		}
+		if s == nil || !externalActionTypes[*s] {
+			return
+		}
+		a := ActionInfo{Type: *s, Location: location, Target: pa.actionTarget(ctx, action, *s)}
+		if !seen[a] {
+			seen[a] = true
+			info.SuspiciousActions = append(info.SuspiciousActions, a)
+		}
+	})
+}
@@ -1,27 +1,177 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ledongthuc/pdf"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
 )
 
 // AnalyzePDF performs comprehensive analysis of a PDF file
 func (pa *PDFAnalyzer) AnalyzePDF(filePath string) (*PDFInfo, error) {
-	info := &PDFInfo{}
+	info := &PDFInfo{Completeness: newAnalysisCompleteness()}
 	
-	// Basic file information
-	if err := pa.getFileInfo(filePath, info); err != nil {
+	// Basic file information; the contents are read once and shared by every analysis
+	data, err := pa.getFileInfo(filePath, info)
+	if err != nil {
 		return nil, fmt.Errorf("error getting file information: %v", err)
 	}
 
+	// Arquivos cortados (download interrompido) confundem os parsers: diagnosticar antes
+	info.TruncationReasons = detectTruncation(data)
+	info.IsTruncated = len(info.TruncationReasons) > 0
+
 	// Analysis using pdfcpu
-	if err := pa.analyzePDFCPU(filePath, info); err != nil {
-		fmt.Printf("Warning: error in pdfcpu analysis: %v\n", err)
+	start := time.Now()
+	err = pa.analyzePDFCPU(filePath, data, info)
+	debugf("pdfcpu analysis of %s took %v", filePath, time.Since(start).Round(time.Millisecond))
+	if err != nil {
+		// Senha errada não é falha de leitura: não adianta tentar o resto
+		if errors.Is(err, errWrongPassword) {
+			return nil, err
+		}
+		fmt.Fprintf(os.Stderr, "Warning: error in pdfcpu analysis: %v\n", err)
+		if pa.Password == "" && strings.Contains(strings.ToLower(err.Error()), "password") {
+			fmt.Fprintln(os.Stderr, "Warning: the document requires a password; use --password to decrypt it")
+		}
+		info.Completeness.Metadata = completenessFailed
+		info.Completeness.Pages = completenessFailed
+		info.Completeness.Signatures = completenessFailed
+		info.Completeness.Fonts = completenessFailed
+		info.Completeness.Images = completenessFailed
+		info.Completeness.Permissions = completenessFailed
+
+		// Recuperar o básico diretamente dos bytes do arquivo
+		if err := pa.analyzeFallback(data, info); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: error in fallback parsing: %v\n", err)
+		} else {
+			info.Completeness.Metadata = completenessPartial
+			info.Completeness.Pages = completenessPartial
+		}
+		// Assinaturas estimadas pelos padrões de bytes
+		pa.estimateSignatures(data, info)
 	}
 
 	// Analysis using ledongthuc/pdf
-	if err := pa.analyzeLedongthuc(filePath, info); err != nil {
-		fmt.Printf("Warning: error in ledongthuc analysis: %v\n", err)
+	start = time.Now()
+	err = pa.analyzeLedongthuc(data, info)
+	debugf("text extraction of %s took %v", filePath, time.Since(start).Round(time.Millisecond))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: error in ledongthuc analysis: %v\n", err)
+		info.Completeness.Text = completenessFailed
+	}
+
+	// Comparar as duas estimativas de texto
+	if pa.CrossCheckText && !info.FallbackParsing {
+		info.TextExtractionDiscrepancy = textLengthsDisagree(info.TotalTextLength, info.ContentStreamTextLength)
+	}
+
+	// Digitalizações sem camada de texto, depois das análises de páginas e de texto
+	info.ImagesPerPage = imagesPerPage(info)
+	info.IsProbablyScanned = isProbablyScanned(info)
+
+	// Classificação heurística do documento
+	info.DocumentClass = documentClass(info)
+
+	// Pontuação de risco para triagem, depois de todas as outras análises
+	info.RiskScore, info.RiskFactors = riskAssessment(info)
+
+	return info, nil
+}
+
+// errWrongPassword is returned when the password given with --password doesn't open the file
+var errWrongPassword = errors.New("wrong password for encrypted PDF")
+
+// pdfConfiguration returns the pdfcpu configuration, carrying the password if one was given.
+// The same password is tried as user and owner password.
+func (pa *PDFAnalyzer) pdfConfiguration() *model.Configuration {
+	conf := model.NewDefaultConfiguration()
+	if pa.Password != "" {
+		conf.UserPW = pa.Password
+		conf.OwnerPW = pa.Password
+	}
+	return conf
+}
+
+// recoverParserPanic turns a panic of a PDF parser into an error; both libraries panic instead
+// of failing on some malformed or truncated files. Use as defer recoverParserPanic(&err).
+func recoverParserPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("parser crashed on malformed input: %v", r)
+	}
+}
+
+// readContext reads and validates a pdfcpu context from the file contents already in memory
+func (pa *PDFAnalyzer) readContext(data []byte) (ctx *model.Context, err error) {
+	defer recoverParserPanic(&err)
+
+	ctx, err = api.ReadContext(bytes.NewReader(data), pa.pdfConfiguration())
+	if err != nil {
+		if pa.Password != "" && strings.Contains(strings.ToLower(err.Error()), "password") {
+			return nil, errWrongPassword
+		}
+		return nil, err
+	}
+	// A validação apaga /AcroForm quando /Fields está vazio, justamente o caso dos formulários
+	// só XFA: guardar a entrada para devolvê-la depois
+	xfaForm := pa.xfaOnlyAcroForm(ctx)
+	if err := api.ValidateContext(ctx); err != nil {
+		return nil, err
+	}
+	if xfaForm != nil && ctx.RootDict != nil {
+		if _, found := ctx.RootDict.Find("AcroForm"); !found {
+			ctx.RootDict["AcroForm"] = xfaForm
+		}
+	}
+	return ctx, nil
+}
+
+// textReader opens the file contents with ledongthuc/pdf, decrypting with the password if
+// one was given
+func (pa *PDFAnalyzer) textReader(data []byte) (r *pdf.Reader, err error) {
+	defer recoverParserPanic(&err)
+
+	if pa.Password == "" {
+		return pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	}
+	// A função é chamada até devolver "": tentar a senha uma única vez
+	tried := false
+	password := func() string {
+		if tried {
+			return ""
+		}
+		tried = true
+		return pa.Password
+	}
+	return pdf.NewReaderEncrypted(bytes.NewReader(data), int64(len(data)), password)
+}
+
+// AnalyzeMetadata extracts only the document metadata (Info dictionary, custom entries and XMP),
+// skipping the page, content and signature analyses
+func (pa *PDFAnalyzer) AnalyzeMetadata(filePath string) (*PDFInfo, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading PDF: %v", err)
+	}
+	ctx, err := pa.readContext(data)
+	if err != nil {
+		return nil, fmt.Errorf("error reading PDF: %v", err)
+	}
+
+	info := &PDFInfo{
+		FileName:     filepath.Base(filePath),
+		FilePath:     filePath,
+		Completeness: newAnalysisCompleteness(),
 	}
+	info.Completeness.Metadata = completenessFull
+	pa.extractMetadata(ctx, info)
 
 	return info, nil
 }
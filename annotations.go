@@ -0,0 +1,134 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// annotationFlagHidden is bit 2 of the annotation /F flags
+const annotationFlagHidden = 1 << 1
+
+// Limits of the annotation listing in the report
+const (
+	maxAnnotationsListed = 10 // annotations listed per type
+	maxAnnotationContent = 80 // characters of /Contents shown per annotation
+)
+
+// pageAnnotations resolves the annotation dictionaries of a page's /Annots array
+func (pa *PDFAnalyzer) pageAnnotations(ctx *model.Context, pageDict types.Dict) []types.Dict {
+	annotsObj, found := pageDict.Find("Annots")
+	if !found || annotsObj == nil {
+		return nil
+	}
+	annots, err := ctx.DereferenceArray(annotsObj)
+	if err != nil {
+		return nil
+	}
+
+	var dicts []types.Dict
+	for _, annotObj := range annots {
+		if annot, err := ctx.DereferenceDict(annotObj); err == nil && annot != nil {
+			dicts = append(dicts, annot)
+		}
+	}
+	return dicts
+}
+
+// markupAnnotationTypes are the markup annotation subtypes (PDF 32000-1, 12.5.6.2): notes,
+// highlights, stamps and other comments a reviewer adds to the page
+var markupAnnotationTypes = map[string]bool{
+	"Text": true, "FreeText": true, "Line": true, "Square": true, "Circle": true,
+	"Polygon": true, "PolyLine": true, "Highlight": true, "Underline": true, "Squiggly": true,
+	"StrikeOut": true, "Stamp": true, "Caret": true, "Ink": true, "FileAttachment": true,
+	"Sound": true, "Redact": true,
+}
+
+// isFormWidget reports whether an annotation is the widget of an AcroForm field: a /Widget
+// that is either a field itself (merged field and widget, with /FT) or the kid of one (/Parent)
+func isFormWidget(annot types.Dict, hasAcroForm bool) bool {
+	if !hasAcroForm {
+		return false
+	}
+	if subtype := annot.NameEntry("Subtype"); subtype == nil || *subtype != "Widget" {
+		return false
+	}
+	_, hasType := annot.Find("FT")
+	_, hasParent := annot.Find("Parent")
+	return hasType || hasParent
+}
+
+// annotationInfo describes an annotation found on a page
+func annotationInfo(annot types.Dict, pageNr int) AnnotationInfo {
+	annotType := "Unknown"
+	if subtype := annot.NameEntry("Subtype"); subtype != nil {
+		annotType = *subtype
+	}
+	return AnnotationInfo{
+		Type:    annotType,
+		Page:    pageNr,
+		Content: strings.Join(strings.Fields(getStringFromDict(annot, "Contents")), " "),
+	}
+}
+
+// annotationsByType groups annotations by type, most frequent type first
+func annotationsByType(annotations []AnnotationInfo) [][]AnnotationInfo {
+	index := make(map[string]int)
+	var groups [][]AnnotationInfo
+	for _, annot := range annotations {
+		i, ok := index[annot.Type]
+		if !ok {
+			i = len(groups)
+			index[annot.Type] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], annot)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if len(groups[i]) != len(groups[j]) {
+			return len(groups[i]) > len(groups[j])
+		}
+		return groups[i][0].Type < groups[j][0].Type
+	})
+	return groups
+}
+
+// shortenText cuts s to at most max characters, marking the cut with "..."
+func shortenText(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max]) + "..."
+}
+
+// annotationNeedsAppearance reports whether an annotation is expected to carry an /AP
+// appearance stream. Popups are drawn by the viewer, links have no visual content of
+// their own and hidden annotations are never shown.
+func annotationNeedsAppearance(annot types.Dict) bool {
+	if subtype := annot.NameEntry("Subtype"); subtype != nil {
+		if *subtype == "Popup" || *subtype == "Link" {
+			return false
+		}
+	}
+	if flags := annot.IntEntry("F"); flags != nil && *flags&annotationFlagHidden != 0 {
+		return false
+	}
+	return true
+}
+
+// hasAppearanceStream reports whether an annotation has an /AP dictionary with a normal (/N) appearance
+func hasAppearanceStream(ctx *model.Context, annot types.Dict) bool {
+	apObj, found := annot.Find("AP")
+	if !found || apObj == nil {
+		return false
+	}
+	ap, err := ctx.DereferenceDict(apObj)
+	if err != nil || ap == nil {
+		return false
+	}
+	n, found := ap.Find("N")
+	return found && n != nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// stringList is a repeatable string flag
+type stringList []string
+
+func (s *stringList) String() string { return strings.Join(*s, ", ") }
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// assertionOperators in match order: two-character operators must be tried first
+var assertionOperators = []string{"==", "!=", ">=", "<=", ">", "<"}
+
+// infoFields flattens the scalar fields of PDFInfo into snake_case keys
+// (e.g. IsEncrypted -> is_encrypted, PDFVersion -> pdf_version)
+func infoFields(info *PDFInfo) map[string]string {
+	fields := make(map[string]string)
+	v := reflect.ValueOf(info).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		key := toSnakeCase(t.Field(i).Name)
+		switch field.Kind() {
+		case reflect.Bool:
+			fields[key] = strconv.FormatBool(field.Bool())
+		case reflect.Int, reflect.Int64:
+			fields[key] = strconv.FormatInt(field.Int(), 10)
+		case reflect.Float64:
+			fields[key] = strconv.FormatFloat(field.Float(), 'f', -1, 64)
+		case reflect.String:
+			fields[key] = field.String()
+		}
+	}
+	return fields
+}
+
+// toSnakeCase converts a Go field name to snake_case, keeping acronyms together
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// evaluateAssertion evaluates an expression such as "is_encrypted==false" or "page_count>=2"
+// against the flattened fields. Numeric operands are compared as numbers, anything else as text.
+func evaluateAssertion(expr string, fields map[string]string) (bool, error) {
+	for _, op := range assertionOperators {
+		idx := strings.Index(expr, op)
+		if idx <= 0 {
+			continue
+		}
+		key := strings.TrimSpace(expr[:idx])
+		expected := strings.Trim(strings.TrimSpace(expr[idx+len(op):]), `"'`)
+		actual, ok := fields[key]
+		if !ok {
+			return false, fmt.Errorf("unknown field %q", key)
+		}
+
+		actualNum, errA := strconv.ParseFloat(actual, 64)
+		expectedNum, errB := strconv.ParseFloat(expected, 64)
+		if errA == nil && errB == nil {
+			switch op {
+			case "==":
+				return actualNum == expectedNum, nil
+			case "!=":
+				return actualNum != expectedNum, nil
+			case ">=":
+				return actualNum >= expectedNum, nil
+			case "<=":
+				return actualNum <= expectedNum, nil
+			case ">":
+				return actualNum > expectedNum, nil
+			case "<":
+				return actualNum < expectedNum, nil
+			}
+		}
+
+		switch op {
+		case "==":
+			return strings.EqualFold(actual, expected), nil
+		case "!=":
+			return !strings.EqualFold(actual, expected), nil
+		default:
+			return false, fmt.Errorf("operator %s needs numeric operands in %q", op, expr)
+		}
+	}
+	return false, fmt.Errorf("no comparison operator in %q", expr)
+}
+
+// checkAssertions returns false if any --assert expression is false or any --fail-on
+// expression is true. Failures are described on the returned messages.
+func checkAssertions(info *PDFInfo, assertions, failOn []string) (bool, []string, error) {
+	fields := infoFields(info)
+	var failures []string
+
+	for _, expr := range assertions {
+		ok, err := evaluateAssertion(expr, fields)
+		if err != nil {
+			return false, nil, err
+		}
+		if !ok {
+			failures = append(failures, fmt.Sprintf("assertion failed: %s", expr))
+		}
+	}
+	for _, expr := range failOn {
+		hit, err := evaluateAssertion(expr, fields)
+		if err != nil {
+			return false, nil, err
+		}
+		if hit {
+			failures = append(failures, fmt.Sprintf("fail-on condition met: %s", expr))
+		}
+	}
+
+	return len(failures) == 0, failures, nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+// Categories of attachments worth pointing out; other files are left uncategorized
+const (
+	attachmentExecutable   = "executable"
+	attachmentScript       = "script"
+	attachmentMacro        = "macro-enabled document"
+	attachmentArchive      = "archive"
+	attachmentLegacyOffice = "legacy Office document"
+)
+
+// dangerousAttachmentCategories run code when opened
+var dangerousAttachmentCategories = map[string]bool{
+	attachmentExecutable: true,
+	attachmentScript:     true,
+	attachmentMacro:      true,
+}
+
+// attachmentSignatures are the leading bytes of file formats, sniffed from the decoded stream
+var attachmentSignatures = []struct {
+	magic    []byte
+	category string
+}{
+	{[]byte("MZ"), attachmentExecutable},      // PE (Windows)
+	{[]byte("\x7fELF"), attachmentExecutable}, // ELF (Linux)
+	{[]byte{0xfe, 0xed, 0xfa, 0xce}, attachmentExecutable},
+	{[]byte{0xfe, 0xed, 0xfa, 0xcf}, attachmentExecutable},
+	{[]byte{0xce, 0xfa, 0xed, 0xfe}, attachmentExecutable},
+	{[]byte{0xcf, 0xfa, 0xed, 0xfe}, attachmentExecutable},
+	{[]byte{0xca, 0xfe, 0xba, 0xbe}, attachmentExecutable}, // Mach-O universal ou classe Java
+	{[]byte("#!"), attachmentScript},
+	{[]byte("PK\x03\x04"), attachmentArchive},
+	{[]byte("Rar!\x1a\x07"), attachmentArchive},
+	{[]byte("7z\xbc\xaf\x27\x1c"), attachmentArchive},
+	{[]byte{0x1f, 0x8b}, attachmentArchive},
+	{[]byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1}, attachmentLegacyOffice},
+}
+
+// attachmentExtensions map file name extensions to categories
+var attachmentExtensions = map[string]string{
+	".exe": attachmentExecutable, ".dll": attachmentExecutable, ".scr": attachmentExecutable,
+	".com": attachmentExecutable, ".msi": attachmentExecutable, ".jar": attachmentExecutable,
+	".app": attachmentExecutable, ".cpl": attachmentExecutable, ".pif": attachmentExecutable,
+	".lnk": attachmentExecutable,
+	".js":  attachmentScript, ".jse": attachmentScript, ".vbs": attachmentScript, ".vbe": attachmentScript,
+	".ps1": attachmentScript, ".bat": attachmentScript, ".cmd": attachmentScript, ".wsf": attachmentScript,
+	".hta": attachmentScript, ".sh": attachmentScript, ".py": attachmentScript,
+	".docm": attachmentMacro, ".dotm": attachmentMacro, ".xlsm": attachmentMacro, ".xltm": attachmentMacro,
+	".xlam": attachmentMacro, ".pptm": attachmentMacro, ".potm": attachmentMacro, ".ppam": attachmentMacro,
+	".zip": attachmentArchive, ".rar": attachmentArchive, ".7z": attachmentArchive, ".gz": attachmentArchive,
+	".tar": attachmentArchive, ".cab": attachmentArchive, ".iso": attachmentArchive,
+	".doc": attachmentLegacyOffice, ".xls": attachmentLegacyOffice, ".ppt": attachmentLegacyOffice,
+}
+
+// attachmentMIMETypes map fragments of the declared MIME type (/Subtype) to categories
+var attachmentMIMETypes = []struct {
+	fragment string
+	category string
+}{
+	{"macroenabled", attachmentMacro},
+	{"x-msdownload", attachmentExecutable},
+	{"x-dosexec", attachmentExecutable},
+	{"x-executable", attachmentExecutable},
+	{"x-mach-binary", attachmentExecutable},
+	{"portable-executable", attachmentExecutable},
+	{"x-msi", attachmentExecutable},
+	{"java-archive", attachmentExecutable},
+	{"x-ms-shortcut", attachmentExecutable},
+	{"javascript", attachmentScript},
+	{"ecmascript", attachmentScript},
+	{"vbscript", attachmentScript},
+	{"x-shellscript", attachmentScript},
+	{"x-bat", attachmentScript},
+	{"x-python", attachmentScript},
+	{"powershell", attachmentScript},
+	{"application/hta", attachmentScript},
+	{"application/zip", attachmentArchive},
+	{"x-rar", attachmentArchive},
+	{"x-7z", attachmentArchive},
+	{"gzip", attachmentArchive},
+	{"x-tar", attachmentArchive},
+	{"application/msword", attachmentLegacyOffice},
+	{"application/vnd.ms-excel", attachmentLegacyOffice},
+	{"application/vnd.ms-powerpoint", attachmentLegacyOffice},
+}
+
+// classifyAttachment categorizes an embedded file by the signature of its contents, its declared
+// MIME type and its name, and reports whether it can run code when opened. A program or script
+// found in the contents wins over a harmless declared type or name, the usual disguise.
+func classifyAttachment(name, subtype string, data []byte) (category string, dangerous bool) {
+	sniffed := ""
+	for _, s := range attachmentSignatures {
+		if bytes.HasPrefix(data, s.magic) {
+			sniffed = s.category
+			break
+		}
+	}
+	declared := ""
+	lower := strings.ToLower(subtype)
+	for _, m := range attachmentMIMETypes {
+		if strings.Contains(lower, m.fragment) {
+			declared = m.category
+			break
+		}
+	}
+	byName := attachmentExtensions[strings.ToLower(filepath.Ext(name))]
+
+	switch {
+	case dangerousAttachmentCategories[sniffed]:
+		category = sniffed
+	case dangerousAttachmentCategories[declared]:
+		category = declared
+	case dangerousAttachmentCategories[byName]:
+		// .docm e afins são ZIP: o nome decide
+		category = byName
+	case sniffed != "":
+		category = sniffed
+	case declared != "":
+		category = declared
+	default:
+		category = byName
+	}
+	return category, dangerousAttachmentCategories[category]
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// collectPDFPaths expands the command-line arguments into the files to analyze. Files are
+// taken as given, whatever their extension; directories contribute their *.pdf files, and
+// those of their subdirectories when recursive is set. A path that cannot be read is kept so
+// that the analysis reports the error for it.
+func collectPDFPaths(args []string, recursive bool) []string {
+	var paths []string
+	for _, arg := range args {
+		st, err := os.Stat(arg)
+		if err != nil || !st.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+
+		var found []string
+		filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: cannot read %s: %v\n", path, err)
+				return nil
+			}
+			if d.IsDir() {
+				if path != arg && !recursive {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.EqualFold(filepath.Ext(path), ".pdf") {
+				found = append(found, path)
+			}
+			return nil
+		})
+		sort.Strings(found)
+		paths = append(paths, found...)
+	}
+	return paths
+}
+
+// batchSummary accumulates the totals shown after analyzing several files
+type batchSummary struct {
+	Scanned   int
+	Encrypted int
+	Signed    int
+	Failed    []string // "path: error" for each file that could not be analyzed
+
+	// Arquivos agrupados por SHA256 e por /ID, na ordem em que aparecem
+	byHash       duplicateGroups
+	byDocumentID duplicateGroups
+	hashOf       map[string]string // SHA256 of each path
+}
+
+// duplicateGroups collects paths under a key, remembering the order in which keys first appear
+type duplicateGroups struct {
+	keys  []string
+	paths map[string][]string
+}
+
+// add files path under key; empty keys are ignored
+func (g *duplicateGroups) add(key, path string) {
+	if key == "" {
+		return
+	}
+	if g.paths == nil {
+		g.paths = make(map[string][]string)
+	}
+	if _, seen := g.paths[key]; !seen {
+		g.keys = append(g.keys, key)
+	}
+	g.paths[key] = append(g.paths[key], path)
+}
+
+// groups returns the groups with more than one path, in order of first appearance
+func (g *duplicateGroups) groups() [][]string {
+	var groups [][]string
+	for _, key := range g.keys {
+		if len(g.paths[key]) > 1 {
+			groups = append(groups, g.paths[key])
+		}
+	}
+	return groups
+}
+
+// add records the outcome of one file; info is nil when the analysis failed
+func (s *batchSummary) add(path string, info *PDFInfo, err error) {
+	s.Scanned++
+	if err != nil {
+		s.Failed = append(s.Failed, fmt.Sprintf("%s: %v", path, err))
+		return
+	}
+	if info == nil {
+		return
+	}
+	if info.IsEncrypted {
+		s.Encrypted++
+	}
+	if info.HasDigitalSignatures {
+		s.Signed++
+	}
+
+	// Sem SHA256 (--hash sem sha256) só o /ID permite agrupar
+	sha := info.Hashes["sha256"]
+	if sha != "" {
+		if s.hashOf == nil {
+			s.hashOf = make(map[string]string)
+		}
+		s.hashOf[path] = sha
+	}
+	s.byHash.add(sha, path)
+	s.byDocumentID.add(info.DocumentID, path)
+}
+
+// duplicates returns the groups of byte-identical files (same SHA256) and of files that are
+// the same logical document (same trailer /ID) but not all byte-identical, e.g. re-saved or
+// edited copies. A group whose files all share one SHA256 is only listed as identical.
+func (s *batchSummary) duplicates() (identical, sameDocument [][]string) {
+	identical = s.byHash.groups()
+	for _, group := range s.byDocumentID.groups() {
+		hashes := make(map[string]bool)
+		for _, path := range group {
+			hashes[s.hashOf[path]] = true
+		}
+		if len(hashes) == 1 && !hashes[""] {
+			continue
+		}
+		sameDocument = append(sameDocument, group)
+	}
+	return identical, sameDocument
+}
+
+// print writes the summary to w
+func (s *batchSummary) print(w io.Writer) {
+	fmt.Fprintln(w, "\n📊 SUMMARY")
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+	fmt.Fprintf(w, "Files scanned: %d\n", s.Scanned)
+	fmt.Fprintf(w, "Encrypted: %d\n", s.Encrypted)
+	fmt.Fprintf(w, "Signed: %d\n", s.Signed)
+	fmt.Fprintf(w, "Errors: %d\n", len(s.Failed))
+	for _, failure := range s.Failed {
+		fmt.Fprintf(w, "  - %s\n", failure)
+	}
+
+	identical, sameDocument := s.duplicates()
+	fmt.Fprintln(w, "\n🔁 DUPLICATES")
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+	if len(identical) == 0 && len(sameDocument) == 0 {
+		fmt.Fprintln(w, "No duplicates found")
+		return
+	}
+	if len(identical) > 0 {
+		fmt.Fprintln(w, "Identical files (same SHA256):")
+		for _, group := range identical {
+			fmt.Fprintf(w, "  - %s\n", strings.Join(group, ", "))
+		}
+	}
+	if len(sameDocument) > 0 {
+		fmt.Fprintln(w, "Same document, re-saved or edited (same /ID):")
+		for _, group := range sameDocument {
+			fmt.Fprintf(w, "  - %s\n", strings.Join(group, ", "))
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import "errors"
+
+var errMalformedBER = errors.New("malformed BER data")
+
+// berToDER re-encodes BER data as DER so that encoding/asn1 accepts it: indefinite lengths
+// become definite and constructed OCTET STRINGs are flattened. Many signing tools emit
+// BER-encoded CMS.
+func berToDER(ber []byte) ([]byte, error) {
+	// Bytes após o elemento (o preenchimento com zeros de /Contents) são ignorados
+	out, _, err := convertBERElement(ber, 0)
+	return out, err
+}
+
+// convertBERElement converts the element at the start of data and returns the rest
+func convertBERElement(data []byte, depth int) ([]byte, []byte, error) {
+	if depth > 64 || len(data) < 2 {
+		return nil, nil, errMalformedBER
+	}
+
+	// Identificador (com número de tag longo)
+	idLen := 1
+	if data[0]&0x1f == 0x1f {
+		for idLen < len(data) && data[idLen]&0x80 != 0 {
+			idLen++
+		}
+		idLen++
+	}
+	if idLen >= len(data) {
+		return nil, nil, errMalformedBER
+	}
+	identifier := data[:idLen]
+	constructed := data[0]&0x20 != 0
+	octetString := data[0] == 0x24 // universal, constructed, tag 4
+
+	// Comprimento
+	pos := idLen
+	lengthByte := data[pos]
+	pos++
+	indefinite := lengthByte == 0x80
+	length := 0
+	switch {
+	case indefinite:
+		if !constructed {
+			return nil, nil, errMalformedBER
+		}
+	case lengthByte&0x80 == 0:
+		length = int(lengthByte)
+	default:
+		n := int(lengthByte & 0x7f)
+		if n > 4 || pos+n > len(data) {
+			return nil, nil, errMalformedBER
+		}
+		for i := 0; i < n; i++ {
+			length = length<<8 | int(data[pos+i])
+		}
+		pos += n
+	}
+
+	if !constructed {
+		if pos+length > len(data) {
+			return nil, nil, errMalformedBER
+		}
+		return encodeDER(identifier, data[pos:pos+length]), data[pos+length:], nil
+	}
+
+	// Elementos filhos, até o fim do comprimento ou até o marcador 00 00
+	var body []byte
+	rest := data[pos:]
+	if !indefinite {
+		if length > len(rest) {
+			return nil, nil, errMalformedBER
+		}
+		rest, body = rest[length:], nil
+		children := data[pos : pos+length]
+		for len(children) > 0 {
+			child, next, err := convertBERElement(children, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			body = appendChild(body, child, octetString)
+			children = next
+		}
+	} else {
+		for {
+			if len(rest) < 2 {
+				return nil, nil, errMalformedBER
+			}
+			if rest[0] == 0 && rest[1] == 0 {
+				rest = rest[2:]
+				break
+			}
+			child, next, err := convertBERElement(rest, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			body = appendChild(body, child, octetString)
+			rest = next
+		}
+	}
+
+	if octetString {
+		return encodeDER([]byte{0x04}, body), rest, nil
+	}
+	return encodeDER(identifier, body), rest, nil
+}
+
+// appendChild adds a converted child to a constructed body; the pieces of a constructed
+// OCTET STRING are concatenated without their headers
+func appendChild(body, child []byte, octetString bool) []byte {
+	if !octetString {
+		return append(body, child...)
+	}
+	_, content := splitDER(child)
+	return append(body, content...)
+}
+
+// splitDER separates a DER element into its header and contents
+func splitDER(der []byte) ([]byte, []byte) {
+	pos := 1
+	if der[0]&0x1f == 0x1f {
+		for der[pos]&0x80 != 0 {
+			pos++
+		}
+		pos++
+	}
+	if der[pos]&0x80 == 0 {
+		return der[:pos+1], der[pos+1:]
+	}
+	n := int(der[pos] & 0x7f)
+	return der[:pos+1+n], der[pos+1+n:]
+}
+
+// encodeDER writes an element with a definite length
+func encodeDER(identifier, content []byte) []byte {
+	out := append([]byte{}, identifier...)
+	n := len(content)
+	switch {
+	case n < 0x80:
+		out = append(out, byte(n))
+	case n < 0x100:
+		out = append(out, 0x81, byte(n))
+	case n < 0x10000:
+		out = append(out, 0x82, byte(n>>8), byte(n))
+	case n < 0x1000000:
+		out = append(out, 0x83, byte(n>>16), byte(n>>8), byte(n))
+	default:
+		out = append(out, 0x84, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(out, content...)
+}
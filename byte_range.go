@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+)
+
+// oidMessageDigest is the CMS signed attribute holding the digest of the signed content
+var oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+
+// Digest algorithms that may appear in a SignerInfo or a timestamp message imprint
+var digestAlgorithms = []struct {
+	oid  asn1.ObjectIdentifier
+	hash crypto.Hash
+}{
+	{asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}, crypto.SHA1},
+	{asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}, crypto.SHA256},
+	{asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}, crypto.SHA384},
+	{asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}, crypto.SHA512},
+}
+
+// Resultados da comparação do digest com os bytes assinados
+const (
+	digestMatch    = "match"
+	digestMismatch = "mismatch"
+)
+
+// messageImprint is the RFC 3161 MessageImprint of a timestamp token
+type messageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+// digestHash maps a digest AlgorithmIdentifier to its hash function
+func digestHash(algorithm pkix.AlgorithmIdentifier) (crypto.Hash, error) {
+	for _, d := range digestAlgorithms {
+		if algorithm.Algorithm.Equal(d.oid) {
+			return d.hash, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported digest algorithm %v", algorithm.Algorithm)
+}
+
+// signedDigest returns the digest a signature claims for its signed bytes: the message imprint
+// of a document timestamp, the encapsulated SHA-1 digest of adbe.pkcs7.sha1 signatures, or the
+// messageDigest signed attribute of a detached CMS signature
+func signedDigest(contents []byte) (crypto.Hash, []byte, error) {
+	sd, err := parseSignedData(contents)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(sd.SignerInfos) == 0 {
+		return 0, nil, errors.New("no signer in the signature")
+	}
+
+	if len(sd.EncapContentInfo.EContent.Bytes) > 0 {
+		var octets []byte
+		if _, err := asn1.Unmarshal(sd.EncapContentInfo.EContent.Bytes, &octets); err != nil {
+			return 0, nil, err
+		}
+		if !sd.EncapContentInfo.EContentType.Equal(oidTSTInfo) {
+			// adbe.pkcs7.sha1: o conteúdo assinado é o próprio digest SHA-1 dos intervalos
+			return crypto.SHA1, octets, nil
+		}
+		var tst struct {
+			Version        int
+			Policy         asn1.ObjectIdentifier
+			MessageImprint messageImprint
+		}
+		if _, err := asn1.Unmarshal(octets, &tst); err != nil {
+			return 0, nil, err
+		}
+		hash, err := digestHash(tst.MessageImprint.HashAlgorithm)
+		return hash, tst.MessageImprint.HashedMessage, err
+	}
+
+	si := sd.SignerInfos[0]
+	var algorithm pkix.AlgorithmIdentifier
+	if _, err := asn1.Unmarshal(si.DigestAlgorithm.FullBytes, &algorithm); err != nil {
+		return 0, nil, err
+	}
+	hash, err := digestHash(algorithm)
+	if err != nil {
+		return 0, nil, err
+	}
+	for rest := si.SignedAttrs.Bytes; len(rest) > 0; {
+		var attr cmsAttribute
+		if rest, err = asn1.Unmarshal(rest, &attr); err != nil {
+			return 0, nil, err
+		}
+		if attr.Type.Equal(oidMessageDigest) && len(attr.Values) > 0 {
+			var digest []byte
+			if _, err := asn1.Unmarshal(attr.Values[0].FullBytes, &digest); err != nil {
+				return 0, nil, err
+			}
+			return hash, digest, nil
+		}
+	}
+	return 0, nil, errors.New("no message digest in the signed attributes")
+}
+
+// analyzeByteRangeCoverage checks what a signature's /ByteRange covers: the two ranges must
+// start at the beginning of the file and leave out only the /Contents hex string, and any bytes
+// past the second range are not signed. It also recomputes the digest over the signed bytes
+// and compares it with the one in the signature.
+func (pa *PDFAnalyzer) analyzeByteRangeCoverage(data []byte, sr signedRange, sigInfo *DigitalSignatureInfo) {
+	br := sr.ByteRange
+	size := int64(len(data))
+	if br[0] < 0 || br[1] < 0 || br[2] < br[0]+br[1] || br[3] < 0 || sr.End() > size {
+		return
+	}
+
+	sigInfo.BytesAfterSignature = size - sr.End()
+	// O intervalo omitido deve ser exatamente "<...>" com o conteúdo da assinatura em hexadecimal
+	gap := br[2] - (br[0] + br[1])
+	onlyContents := len(sr.Contents) == 0 || gap == int64(2*len(sr.Contents)+2)
+	sigInfo.CoversWholeDocument = br[0] == 0 && onlyContents && sigInfo.BytesAfterSignature == 0
+
+	hash, expected, err := signedDigest(sr.Contents)
+	if err != nil || !hash.Available() {
+		return
+	}
+	h := hash.New()
+	h.Write(data[br[0] : br[0]+br[1]])
+	h.Write(data[br[2]:sr.End()])
+	if bytes.Equal(h.Sum(nil), expected) {
+		sigInfo.ByteRangeDigest = digestMatch
+	} else {
+		sigInfo.ByteRangeDigest = digestMismatch
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Color space kinds, as far as printing in color or black ink is concerned
+const (
+	colorKindGray    = "gray"    // DeviceGray, CalGray, single-channel ICC
+	colorKindRGB     = "rgb"     // color unless all components are equal
+	colorKindCMYK    = "cmyk"    // color unless only the black channel is used
+	colorKindBlack   = "black"   // Separation/DeviceN using only black ink
+	colorKindPattern = "pattern" // patterns and shadings are assumed to be in color
+	colorKindColor   = "color"   // always color (Lab, spot colors, colored palettes)
+)
+
+// colorTolerance is how far components may differ and still count as neutral gray
+const colorTolerance = 0.01
+
+// maxFormDepth limits recursion into nested form XObjects
+const maxFormDepth = 8
+
+// resourcesDict resolves the /Resources dictionary of a page or form XObject
+func (pa *PDFAnalyzer) resourcesDict(ctx *model.Context, d types.Dict) types.Dict {
+	obj, found := d.Find("Resources")
+	if !found || obj == nil {
+		return nil
+	}
+	res, err := ctx.DereferenceDict(obj)
+	if err != nil {
+		return nil
+	}
+	return res
+}
+
+// namedResource resolves an entry of a resource category, e.g. ("XObject", "/Im1")
+func (pa *PDFAnalyzer) namedResource(ctx *model.Context, resources types.Dict, category, name string) types.Object {
+	if resources == nil {
+		return nil
+	}
+	catObj, found := resources.Find(category)
+	if !found || catObj == nil {
+		return nil
+	}
+	cat, err := ctx.DereferenceDict(catObj)
+	if err != nil || cat == nil {
+		return nil
+	}
+	obj, found := cat.Find(strings.TrimPrefix(name, "/"))
+	if !found {
+		return nil
+	}
+	return obj
+}
+
+// contentUsesColor reports whether content would print in color: whether it sets a non-neutral
+// color or draws color images or shadings, itself or in the form XObjects it paints. ops is the
+// parsed content stream.
+func (pa *PDFAnalyzer) contentUsesColor(ctx *model.Context, ops []contentOp, resources types.Dict) bool {
+	// O espaço de cor atual faz parte do estado gráfico, salvo por q e restaurado por Q
+	type colorState struct{ fill, stroke string }
+	state := colorState{colorKindGray, colorKindGray}
+	var saved []colorState
+	usesColor := false
+
+	pa.walkContent(ctx, ops, resources, func(op contentOp, resources types.Dict) bool {
+		switch op.Operator {
+		case "q":
+			saved = append(saved, state)
+		case "Q":
+			if n := len(saved); n > 0 {
+				state, saved = saved[n-1], saved[:n-1]
+			}
+		case "rg", "RG":
+			usesColor = colorValuesAreColor(colorKindRGB, op.Operands)
+		case "k", "K":
+			usesColor = colorValuesAreColor(colorKindCMYK, op.Operands)
+		case "cs", "CS":
+			if len(op.Operands) == 0 {
+				break
+			}
+			kind := pa.classifyColorSpace(ctx, resources, types.Name(strings.TrimPrefix(op.Operands[0], "/")), 0).Kind
+			if op.Operator == "cs" {
+				state.fill = kind
+			} else {
+				state.stroke = kind
+			}
+		case "sc", "scn":
+			usesColor = colorValuesAreColor(state.fill, op.Operands)
+		case "SC", "SCN":
+			usesColor = colorValuesAreColor(state.stroke, op.Operands)
+		case "sh":
+			if len(op.Operands) > 0 {
+				if shading, err := ctx.DereferenceDict(pa.namedResource(ctx, resources, "Shading", op.Operands[0])); err == nil && shading != nil {
+					usesColor = pa.dictColorSpaceIsColor(ctx, resources, shading)
+				}
+			}
+		case "BI":
+			usesColor = inlineImageIsColor(op.Operands)
+		case "Do":
+			// Formulários são percorridos por walkContent; aqui só as imagens
+			if len(op.Operands) == 0 {
+				break
+			}
+			xobj, _, err := ctx.DereferenceStreamDict(pa.namedResource(ctx, resources, "XObject", op.Operands[0]))
+			if err != nil || xobj == nil {
+				break
+			}
+			if subtype := xobj.Dict.NameEntry("Subtype"); subtype == nil || *subtype != "Image" {
+				break
+			}
+			// Máscaras de imagem são pintadas com a cor de preenchimento atual
+			if mask := xobj.Dict.BooleanEntry("ImageMask"); mask != nil && *mask {
+				break
+			}
+			usesColor = pa.dictColorSpaceIsColor(ctx, resources, xobj.Dict)
+		}
+		return !usesColor
+	})
+	return usesColor
+}
+
+// dictColorSpaceIsColor reports whether the /ColorSpace of an image or shading is a color one.
+// Without pixel inspection any RGB or CMYK image is assumed to be in color.
+func (pa *PDFAnalyzer) dictColorSpaceIsColor(ctx *model.Context, resources types.Dict, d types.Dict) bool {
+	csObj, found := d.Find("ColorSpace")
+	if !found || csObj == nil {
+		// JPEG 2000 carrega o espaço de cor no próprio fluxo
+		if filter := d.NameEntry("Filter"); filter != nil && *filter == "JPXDecode" {
+			return true
+		}
+		return false
+	}
+	switch pa.classifyColorSpace(ctx, resources, csObj, 0).Kind {
+	case colorKindGray, colorKindBlack:
+		return false
+	}
+	return true
+}
+
+// paletteIsNeutral reports whether every entry of an Indexed palette is a neutral gray
+func paletteIsNeutral(base string, lookup []byte) bool {
+	components := 3
+	if base == colorKindCMYK {
+		components = 4
+	} else if base != colorKindRGB {
+		return false
+	}
+	if len(lookup) == 0 {
+		return false
+	}
+	for i := 0; i+components <= len(lookup); i += components {
+		values := make([]float64, components)
+		for j := 0; j < components; j++ {
+			values[j] = float64(lookup[i+j]) / 255
+		}
+		if componentsAreColor(base, values) {
+			return false
+		}
+	}
+	return true
+}
+
+// colorValuesAreColor reports whether operands of a color operator select a non-neutral color
+// in a color space of the given kind
+func colorValuesAreColor(kind string, operands []string) bool {
+	switch kind {
+	case colorKindGray, colorKindBlack:
+		return false
+	case colorKindPattern, colorKindColor:
+		return true
+	}
+
+	var values []float64
+	for _, operand := range operands {
+		if v, err := strconv.ParseFloat(operand, 64); err == nil {
+			values = append(values, v)
+		}
+	}
+	return componentsAreColor(kind, values)
+}
+
+// componentsAreColor reports whether RGB or CMYK component values (0..1) are a non-neutral color
+func componentsAreColor(kind string, values []float64) bool {
+	switch kind {
+	case colorKindRGB:
+		if len(values) < 3 {
+			return false
+		}
+		return math.Abs(values[0]-values[1]) > colorTolerance || math.Abs(values[1]-values[2]) > colorTolerance
+	case colorKindCMYK:
+		if len(values) < 4 {
+			return false
+		}
+		// Somente o canal preto (K) imprime em preto e branco
+		return values[0] > colorTolerance || values[1] > colorTolerance || values[2] > colorTolerance
+	}
+	return false
+}
+
+// inlineImageIsColor reports whether inline image parameters (operands of BI) select a color space
+func inlineImageIsColor(params []string) bool {
+	for i := 0; i+1 < len(params); i++ {
+		if params[i] != "/CS" && params[i] != "/ColorSpace" {
+			continue
+		}
+		switch params[i+1] {
+		case "/G", "/DeviceGray", "/CalGray":
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// colorCoverageLabel turns the share of color pages into a rough indicator for print quotes
+func colorCoverageLabel(colorPages, totalPages int) string {
+	if totalPages == 0 {
+		return "None"
+	}
+	share := float64(colorPages) / float64(totalPages)
+	switch {
+	case colorPages == 0:
+		return "None"
+	case share < 0.25:
+		return "Low"
+	case share < 0.75:
+		return "Medium"
+	}
+	return "High"
+}
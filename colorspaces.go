@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// deviceColorOperators are the content operators that select a device color space directly,
+// without a /ColorSpace resource
+var deviceColorOperators = map[string]string{
+	"g": "DeviceGray", "G": "DeviceGray",
+	"rg": "DeviceRGB", "RG": "DeviceRGB",
+	"k": "DeviceCMYK", "K": "DeviceCMYK",
+}
+
+// abbreviatedColorSpaces are the short names allowed in inline images
+var abbreviatedColorSpaces = map[string]string{
+	"G":    "DeviceGray",
+	"RGB":  "DeviceRGB",
+	"CMYK": "DeviceCMYK",
+	"I":    "Indexed",
+}
+
+// processColorants are the Separation and DeviceN colorants that are not spot colors: the inks
+// of four-color printing, and All and None, which are not inks. The value tells whether the
+// colorant prints with black ink only.
+var processColorants = map[string]bool{
+	"Cyan": false, "Magenta": false, "Yellow": false, "Black": true, "All": true, "None": true,
+}
+
+// isBlackColorant reports whether a Separation/DeviceN colorant prints with black ink only
+func isBlackColorant(name string) bool {
+	return processColorants[name]
+}
+
+// isSpotColorant reports whether a Separation/DeviceN colorant is a spot color
+func isSpotColorant(name string) bool {
+	_, process := processColorants[name]
+	return !process
+}
+
+// colorantName decodes a colorant name, e.g. "PANTONE#20185#20C" to "PANTONE 185 C"
+func colorantName(name types.Name) string {
+	if decoded, err := types.DecodeName(string(name)); err == nil {
+		return decoded
+	}
+	return string(name)
+}
+
+// colorSpace describes a color space for the report and for print classification
+type colorSpace struct {
+	Label string   // e.g. "DeviceCMYK", "Separation (PANTONE 185 C)", "ICCBased (RGB)"; empty when unknown
+	Kind  string   // colorKindGray, colorKindRGB, ...
+	Spots []string // spot colorants it uses
+}
+
+// classifyColorSpace describes a color space given by name or array, looking names up in the
+// /ColorSpace resources
+func (pa *PDFAnalyzer) classifyColorSpace(ctx *model.Context, resources types.Dict, csObj types.Object, depth int) colorSpace {
+	if depth > maxResourceDepth {
+		return colorSpace{Kind: colorKindColor}
+	}
+	obj, err := ctx.Dereference(csObj)
+	if err != nil || obj == nil {
+		return colorSpace{Kind: colorKindGray}
+	}
+
+	switch v := obj.(type) {
+	case types.Name:
+		switch string(v) {
+		case "DeviceGray", "G":
+			return colorSpace{Label: "DeviceGray", Kind: colorKindGray}
+		case "CalGray":
+			return colorSpace{Label: "CalGray", Kind: colorKindGray}
+		case "DeviceRGB", "RGB":
+			return colorSpace{Label: "DeviceRGB", Kind: colorKindRGB}
+		case "CalRGB":
+			return colorSpace{Label: "CalRGB", Kind: colorKindRGB}
+		case "DeviceCMYK", "CMYK":
+			return colorSpace{Label: "DeviceCMYK", Kind: colorKindCMYK}
+		case "Pattern":
+			return colorSpace{Label: "Pattern", Kind: colorKindPattern}
+		case "Indexed", "I":
+			return colorSpace{Label: "Indexed", Kind: colorKindColor}
+		}
+		if named := pa.namedResource(ctx, resources, "ColorSpace", string(v)); named != nil {
+			return pa.classifyColorSpace(ctx, resources, named, depth+1)
+		}
+		return colorSpace{Label: string(v), Kind: colorKindGray}
+
+	case types.Array:
+		if len(v) == 0 {
+			return colorSpace{Kind: colorKindGray}
+		}
+		family, _ := v[0].(types.Name)
+		cs := colorSpace{Label: string(family)}
+		switch string(family) {
+		case "CalGray":
+			cs.Kind = colorKindGray
+		case "CalRGB":
+			cs.Kind = colorKindRGB
+		case "Lab":
+			cs.Kind = colorKindColor
+		case "ICCBased":
+			cs.Kind = colorKindRGB
+			if len(v) > 1 {
+				if sd, _, err := ctx.DereferenceStreamDict(v[1]); err == nil && sd != nil {
+					if n := sd.Dict.IntEntry("N"); n != nil {
+						switch *n {
+						case 1:
+							cs.Label, cs.Kind = "ICCBased (Gray)", colorKindGray
+						case 3:
+							cs.Label = "ICCBased (RGB)"
+						case 4:
+							cs.Label, cs.Kind = "ICCBased (CMYK)", colorKindCMYK
+						}
+					}
+				}
+			}
+		case "Indexed", "I":
+			cs.Label, cs.Kind = "Indexed", colorKindColor
+			if len(v) > 1 {
+				base := pa.classifyColorSpace(ctx, resources, v[1], depth+1)
+				if base.Label != "" {
+					cs.Label = fmt.Sprintf("Indexed (%s)", base.Label)
+				}
+				cs.Spots = base.Spots
+				// Uma paleta sobre cinza, ou só com cinzas, imprime em preto e branco
+				if len(v) >= 4 && (base.Kind == colorKindGray || base.Kind == colorKindBlack ||
+					paletteIsNeutral(base.Kind, []byte(pa.stringOrStreamText(ctx, v[3])))) {
+					cs.Kind = colorKindGray
+				}
+			}
+		case "Pattern":
+			cs.Kind = colorKindPattern
+			// Padrões sem cor trazem o espaço de cor subjacente
+			if len(v) > 1 {
+				base := pa.classifyColorSpace(ctx, resources, v[1], depth+1)
+				if base.Label != "" {
+					cs.Label = fmt.Sprintf("Pattern (%s)", base.Label)
+				}
+				cs.Spots = base.Spots
+			}
+		case "Separation":
+			cs.Kind = colorKindColor
+			if len(v) > 1 {
+				if colorant, ok := v[1].(types.Name); ok {
+					name := colorantName(colorant)
+					cs.Label = fmt.Sprintf("Separation (%s)", name)
+					if isBlackColorant(name) {
+						cs.Kind = colorKindBlack
+					}
+					if isSpotColorant(name) {
+						cs.Spots = []string{name}
+					}
+				}
+			}
+		case "DeviceN":
+			cs.Kind = colorKindColor
+			if len(v) > 1 {
+				if colorants, err := ctx.DereferenceArray(v[1]); err == nil {
+					names := make([]string, 0, len(colorants))
+					allBlack := true
+					for _, c := range colorants {
+						colorant, ok := c.(types.Name)
+						if !ok {
+							allBlack = false
+							continue
+						}
+						name := colorantName(colorant)
+						names = append(names, name)
+						if !isBlackColorant(name) {
+							allBlack = false
+						}
+						if isSpotColorant(name) {
+							cs.Spots = append(cs.Spots, name)
+						}
+					}
+					cs.Label = fmt.Sprintf("DeviceN (%s)", strings.Join(names, ", "))
+					if allBlack {
+						cs.Kind = colorKindBlack
+					}
+				}
+			}
+		default:
+			cs.Kind = pa.classifyColorSpace(ctx, resources, family, depth+1).Kind
+		}
+		return cs
+	}
+	return colorSpace{Kind: colorKindGray}
+}
+
+// collectColorSpaces adds the color spaces of a resources dictionary to spaces: the /ColorSpace
+// resources and those of images and shadings, following form XObjects. visited holds the object
+// numbers of the XObjects already walked.
+func (pa *PDFAnalyzer) collectColorSpaces(ctx *model.Context, resources types.Dict, spaces, spots map[string]bool, visited map[int]bool, depth int) {
+	add := func(csObj types.Object) {
+		cs := pa.classifyColorSpace(ctx, resources, csObj, 0)
+		if cs.Label != "" {
+			spaces[cs.Label] = true
+		}
+		for _, spot := range cs.Spots {
+			spots[spot] = true
+		}
+	}
+	for _, obj := range pa.resourceCategory(ctx, resources, "ColorSpace") {
+		add(obj)
+	}
+	for _, obj := range pa.resourceCategory(ctx, resources, "Shading") {
+		if shading, err := ctx.DereferenceDict(obj); err == nil && shading != nil {
+			if csObj, found := shading.Find("ColorSpace"); found && csObj != nil {
+				add(csObj)
+			}
+		}
+	}
+
+	for _, obj := range pa.resourceCategory(ctx, resources, "XObject") {
+		if ref, ok := obj.(types.IndirectRef); ok {
+			if visited[int(ref.ObjectNumber)] {
+				continue
+			}
+			visited[int(ref.ObjectNumber)] = true
+		}
+		sd, _, err := ctx.DereferenceStreamDict(obj)
+		if err != nil || sd == nil {
+			continue
+		}
+		subtype := sd.Dict.NameEntry("Subtype")
+		if subtype == nil {
+			continue
+		}
+		switch *subtype {
+		case "Image":
+			if csObj, found := sd.Dict.Find("ColorSpace"); found && csObj != nil {
+				add(csObj)
+			}
+		case "Form":
+			if depth < maxResourceDepth {
+				pa.collectColorSpaces(ctx, pa.resourcesDict(ctx, sd.Dict), spaces, spots, visited, depth+1)
+			}
+		}
+	}
+}
+
+// contentColorSpaces adds to spaces the device color spaces that content selects without a
+// /ColorSpace resource (operators such as rg or k, cs with a device name, inline images),
+// following the form XObjects it paints
+func (pa *PDFAnalyzer) contentColorSpaces(ctx *model.Context, ops []contentOp, resources types.Dict, spaces map[string]bool) {
+	pa.walkContent(ctx, ops, resources, func(op contentOp, _ types.Dict) bool {
+		if cs, ok := deviceColorOperators[op.Operator]; ok {
+			spaces[cs] = true
+		}
+		switch op.Operator {
+		case "cs", "CS":
+			// Nomes de dispositivo não passam pelos recursos
+			if len(op.Operands) > 0 {
+				switch name := strings.TrimPrefix(op.Operands[0], "/"); name {
+				case "DeviceGray", "DeviceRGB", "DeviceCMYK":
+					spaces[name] = true
+				}
+			}
+		case "BI":
+			for i := 0; i+1 < len(op.Operands); i++ {
+				if op.Operands[i] == "/CS" || op.Operands[i] == "/ColorSpace" {
+					// Outros nomes são recursos, coletados por collectColorSpaces
+					name := strings.TrimPrefix(op.Operands[i+1], "/")
+					if full, ok := abbreviatedColorSpaces[name]; ok {
+						spaces[full] = true
+					} else if strings.HasPrefix(name, "Device") {
+						spaces[name] = true
+					}
+					break
+				}
+			}
+		}
+		return true
+	})
+}
+
+// pageColorSpaces returns the sorted color spaces a page uses: its color space, image and
+// shading resources, and the device color spaces its content selects with operators such as
+// rg or k. ops is the page's parsed content. Spot colorants are added to spots.
+func (pa *PDFAnalyzer) pageColorSpaces(ctx *model.Context, resources types.Dict, ops []contentOp, spots map[string]bool) []string {
+	spaces := make(map[string]bool)
+	pa.collectColorSpaces(ctx, resources, spaces, spots, make(map[int]bool), 0)
+	pa.contentColorSpaces(ctx, ops, resources, spaces)
+
+	labels := make([]string, 0, len(spaces))
+	for label := range spaces {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Completeness states of an analysis feature
+const (
+	completenessFull    = "full"    // ran over the whole document
+	completenessPartial = "partial" // ran, but some parts could not be read or were approximated
+	completenessSkipped = "skipped" // not applicable or not performed; the related fields carry no information
+	completenessFailed  = "failed"  // attempted and failed; the related fields carry no information
+)
+
+// AnalysisCompleteness tells, per feature, whether the corresponding fields can be trusted.
+// A zero ImagesCount only means "no images" when Images is "full".
+type AnalysisCompleteness struct {
+	Metadata    string
+	Pages       string
+	Text        string
+	Fonts       string
+	Images      string
+	Signatures  string
+	Permissions string
+}
+
+// newAnalysisCompleteness returns the initial state: nothing has run yet
+func newAnalysisCompleteness() AnalysisCompleteness {
+	return AnalysisCompleteness{
+		Metadata:    completenessSkipped,
+		Pages:       completenessSkipped,
+		Text:        completenessSkipped,
+		Fonts:       completenessSkipped,
+		Images:      completenessSkipped,
+		Signatures:  completenessSkipped,
+		Permissions: completenessSkipped,
+	}
+}
+
+// incompleteFeatures lists the features that ran partially or failed, e.g. "text (partial)".
+// Skipped features are left out since they are expected for many documents.
+func (c AnalysisCompleteness) incompleteFeatures() []string {
+	features := []struct {
+		name, state string
+	}{
+		{"metadata", c.Metadata},
+		{"pages", c.Pages},
+		{"text", c.Text},
+		{"fonts", c.Fonts},
+		{"images", c.Images},
+		{"signatures", c.Signatures},
+		{"permissions", c.Permissions},
+	}
+
+	var incomplete []string
+	for _, f := range features {
+		if f.state == completenessPartial || f.state == completenessFailed {
+			incomplete = append(incomplete, fmt.Sprintf("%s (%s)", f.name, f.state))
+		}
+	}
+	return incomplete
+}
+
+// printCompletenessWarning prints the features whose results are incomplete, if any
+func printCompletenessWarning(w io.Writer, c AnalysisCompleteness) {
+	if incomplete := c.incompleteFeatures(); len(incomplete) > 0 {
+		fmt.Fprintf(w, "Warning: incomplete analysis: %s\n", strings.Join(incomplete, ", "))
+	}
+}
@@ -1,6 +1,10 @@
 package main
 
 import (
+	"fmt"
+	"os"
+	"sort"
+
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
 )
@@ -8,6 +12,15 @@ import (
 // analyzePages analyzes page information from the PDF
 func (pa *PDFAnalyzer) analyzePages(ctx *model.Context, info *PDFInfo) {
 	info.Pages = make([]PageInfo, ctx.PageCount)
+	info.Completeness.Pages = completenessFull
+	info.Completeness.Images = completenessFull
+	info.ImagesCount = 0
+	info.Annotations = nil
+	info.WidgetAnnotationCount = 0
+	hasAcroForm := pa.acroFormDict(ctx) != nil
+	info.PrintIntended = pa.hasPDFXOutputIntent(ctx)
+	colorSpaces := make(map[string]bool)
+	spots := make(map[string]bool)
 	
 	for i := 1; i <= ctx.PageCount; i++ {
 		pageInfo := PageInfo{
@@ -15,31 +28,86 @@ func (pa *PDFAnalyzer) analyzePages(ctx *model.Context, info *PDFInfo) {
 		}
 
 		// Obter informações da página
-		pageDict, _, _, err := ctx.PageDict(i, false)
-		if err == nil && pageDict != nil {
-			// MediaBox para dimensões
-			if mediaBox := pageDict.ArrayEntry("MediaBox"); mediaBox != nil && len(mediaBox) >= 4 {
-				if width, ok := mediaBox[2].(types.Float); ok {
-					pageInfo.Width = float64(width)
-				}
-				if height, ok := mediaBox[3].(types.Float); ok {
-					pageInfo.Height = float64(height)
-				}
+		pageDict, _, inherited, err := ctx.PageDict(i, true)
+		var resources types.Dict
+		var ops []contentOp
+		if err != nil || pageDict == nil {
+			info.Completeness.Pages = completenessPartial
+			info.Completeness.Images = completenessPartial
+		} else {
+			// O conteúdo é lido e analisado uma única vez para imagens, cor e espaços de cor
+			resources = pa.pageDictResources(ctx, pageDict, inherited)
+			ops = pa.pageContentOps(ctx, pageDict, i)
+
+			// Limites da página; a MediaBox dá as dimensões
+			pa.readPageBoxes(ctx, pageDict, &pageInfo)
+			if pageInfo.MediaBox != nil {
+				pageInfo.Width = pageInfo.MediaBox.Width()
+				pageInfo.Height = pageInfo.MediaBox.Height()
 			}
 
-			// Rotação
-			if rotate := pageDict.IntEntry("Rotate"); rotate != nil {
-				pageInfo.Rotation = *rotate
+			// Rotação, possivelmente herdada da árvore de páginas
+			if rotateObj := pa.inheritedPageAttribute(ctx, pageDict, "Rotate"); rotateObj != nil {
+				if rotate, err := ctx.DereferenceInteger(rotateObj); err == nil && rotate != nil {
+					pageInfo.Rotation = normalizeRotation(int(*rotate))
+				}
 			}
+			pageInfo.EffectiveWidth, pageInfo.EffectiveHeight = effectiveSize(pageInfo)
 
-			// Verificar se há anotações
-			if annotArray := pageDict.ArrayEntry("Annots"); annotArray != nil {
-				pageInfo.ImageCount = len(annotArray) // Simplified approximation
+			for _, annot := range pa.pageAnnotations(ctx, pageDict) {
+				// Widgets de campos do formulário não contam como anotações
+				if isFormWidget(annot, hasAcroForm) {
+					info.WidgetAnnotationCount++
+				} else {
+					annotInfo := annotationInfo(annot, i)
+					info.HasAnnotations = true
+					info.HasMarkupAnnotations = info.HasMarkupAnnotations || markupAnnotationTypes[annotInfo.Type]
+					info.Annotations = append(info.Annotations, annotInfo)
+				}
+				// Anotações sem fluxo de aparência podem não ser impressas
+				if annotationNeedsAppearance(annot) && !hasAppearanceStream(ctx, annot) {
+					info.AnnotationsWithoutAppearance++
+				}
 			}
 		}
 
+		if pageInfo.EffectiveWidth > 0 && pageInfo.EffectiveHeight > 0 {
+			pageInfo.PaperSize = pageSizeLabel(pageInfo.EffectiveWidth, pageInfo.EffectiveHeight)
+		}
+
+		// Imagens (XObjects e imagens em linha)
+		pageInfo.ImageCount = pa.pageImageCount(ctx, resources, ops)
+		info.ImagesCount += pageInfo.ImageCount
+
+		// Classificação para impressão: colorida ou preto e branco
+		pageInfo.IsColor = pa.contentUsesColor(ctx, ops, resources)
+		if pageInfo.IsColor {
+			info.ColorPageCount++
+		} else {
+			info.BlackWhitePageCount++
+		}
+		pageInfo.ColorSpaces = pa.pageColorSpaces(ctx, resources, ops, spots)
+		pageInfo.HasTransparency = pa.pageHasTransparency(ctx, i)
+		info.HasTransparency = info.HasTransparency || pageInfo.HasTransparency
+		for _, cs := range pageInfo.ColorSpaces {
+			colorSpaces[cs] = true
+		}
+
+		pageInfo.BoxIssues = pageBoxIssues(pageInfo, info.PrintIntended)
+
 		info.Pages[i-1] = pageInfo
 	}
+
+	info.ColorSpaces = make([]string, 0, len(colorSpaces))
+	for cs := range colorSpaces {
+		info.ColorSpaces = append(info.ColorSpaces, cs)
+	}
+	sort.Strings(info.ColorSpaces)
+	info.SpotColors = make([]string, 0, len(spots))
+	for spot := range spots {
+		info.SpotColors = append(info.SpotColors, spot)
+	}
+	sort.Strings(info.SpotColors)
 }
 
 // extractBookmarks extracts bookmark information from the PDF
@@ -48,8 +116,31 @@ func (pa *PDFAnalyzer) extractBookmarks(ctx *model.Context, info *PDFInfo) {
 	// This is a placeholder implementation
 }
 
-// extractAttachments extracts attachment information from the PDF
+// extractAttachments extracts attachment information from the PDF and, with
+// ExtractAttachmentsDir, saves the embedded files to disk
 func (pa *PDFAnalyzer) extractAttachments(ctx *model.Context, info *PDFInfo) {
-	// TODO: Implement attachment extraction
-	// This is a placeholder implementation
+	files := pa.embeddedFiles(ctx)
+	for _, file := range files {
+		attachment := AttachmentInfo{Name: file.Name, Size: int64(len(file.Data)), Type: file.Subtype}
+		attachment.Category, attachment.Dangerous = classifyAttachment(file.Name, file.Subtype, file.Data)
+		if attachment.Type == "" {
+			attachment.Type = "unknown type"
+		}
+		info.Attachments = append(info.Attachments, attachment)
+	}
+
+	if pa.ExtractAttachmentsDir == "" || len(files) == 0 {
+		return
+	}
+	if err := os.MkdirAll(pa.ExtractAttachmentsDir, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not create %s: %v\n", pa.ExtractAttachmentsDir, err)
+		return
+	}
+	for _, file := range files {
+		if err := writeAttachment(pa.ExtractAttachmentsDir, file); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipped attachment: %v\n", err)
+			continue
+		}
+		info.AttachmentsExtracted++
+	}
 }
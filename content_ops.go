@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// contentOp is one operator of a content stream together with its operands. Operands are kept
+// as raw tokens: names keep their leading "/", strings and arrays keep their delimiters.
+type contentOp struct {
+	Operator string
+	Operands []string
+}
+
+// isContentWhitespace reports whether b is PDF whitespace
+func isContentWhitespace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f' || b == 0
+}
+
+// isContentDelimiter reports whether b is a PDF delimiter character
+func isContentDelimiter(b byte) bool {
+	switch b {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+// parseContentOps splits a decoded content stream into operators. It is deliberately lenient:
+// malformed input yields fewer operators rather than an error.
+func parseContentOps(data []byte) []contentOp {
+	ops, _ := scanContentOps(data)
+	return ops
+}
+
+// scanContentOps splits data into operators and also returns the operands left over after
+// the last operator
+func scanContentOps(data []byte) ([]contentOp, []string) {
+	var ops []contentOp
+	var operands []string
+
+	i := 0
+	for i < len(data) {
+		c := data[i]
+		switch {
+		case isContentWhitespace(c):
+			i++
+
+		case c == '%':
+			for i < len(data) && data[i] != '\n' && data[i] != '\r' {
+				i++
+			}
+
+		case c == '(':
+			end := skipLiteralString(data, i)
+			operands = append(operands, string(data[i:end]))
+			i = end
+
+		case c == '<' && i+1 < len(data) && data[i+1] == '<':
+			end := skipBalanced(data, i, "<<", ">>")
+			operands = append(operands, string(data[i:end]))
+			i = end
+
+		case c == '<':
+			end := bytes.IndexByte(data[i:], '>')
+			if end < 0 {
+				end = len(data) - i - 1
+			}
+			operands = append(operands, string(data[i:i+end+1]))
+			i += end + 1
+
+		case c == '[':
+			end := skipBalanced(data, i, "[", "]")
+			operands = append(operands, string(data[i:end]))
+			i = end
+
+		case c == '/':
+			start := i
+			i++
+			for i < len(data) && !isContentWhitespace(data[i]) && !isContentDelimiter(data[i]) {
+				i++
+			}
+			operands = append(operands, string(data[start:i]))
+
+		case isContentDelimiter(c):
+			// Delimitador solto (">", "]", "{", ...): ignorar
+			i++
+
+		default:
+			start := i
+			for i < len(data) && !isContentWhitespace(data[i]) && !isContentDelimiter(data[i]) {
+				i++
+			}
+			token := string(data[start:i])
+			if isOperandToken(token) {
+				operands = append(operands, token)
+				continue
+			}
+			ops = append(ops, contentOp{Operator: token, Operands: operands})
+			operands = nil
+
+			// Imagem embutida: os parâmetros vão até ID, os dados binários até EI
+			if token == "BI" {
+				i = parseInlineImage(data, i, &ops)
+			}
+		}
+	}
+	return ops, operands
+}
+
+// isOperandToken reports whether a regular token is an operand (number, boolean or null)
+func isOperandToken(token string) bool {
+	if token == "true" || token == "false" || token == "null" {
+		return true
+	}
+	for j := 0; j < len(token); j++ {
+		c := token[j]
+		if (c < '0' || c > '9') && c != '.' && c != '-' && c != '+' {
+			return false
+		}
+	}
+	return token != ""
+}
+
+// skipLiteralString returns the offset just past the literal string starting at data[start]
+func skipLiteralString(data []byte, start int) int {
+	depth := 0
+	for i := start; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(data)
+}
+
+// skipBalanced returns the offset just past the construct opened at data[start], honoring
+// nesting and literal strings
+func skipBalanced(data []byte, start int, open, close string) int {
+	depth := 0
+	for i := start; i < len(data); {
+		switch {
+		case data[i] == '(':
+			i = skipLiteralString(data, i)
+		case bytes.HasPrefix(data[i:], []byte(open)):
+			depth++
+			i += len(open)
+		case bytes.HasPrefix(data[i:], []byte(close)):
+			depth--
+			i += len(close)
+			if depth == 0 {
+				return i
+			}
+		default:
+			i++
+		}
+	}
+	return len(data)
+}
+
+// parseInlineImage reads the parameters of an inline image that starts right after "BI",
+// records them as the operands of the BI operator and returns the offset after "EI"
+func parseInlineImage(data []byte, i int, ops *[]contentOp) int {
+	idx := len(*ops) - 1
+
+	// Parâmetros até o operador ID
+	paramsEnd := i
+	for j := i; j+2 <= len(data); j++ {
+		if data[j] == 'I' && data[j+1] == 'D' && (j == 0 || isContentWhitespace(data[j-1])) &&
+			(j+2 == len(data) || isContentWhitespace(data[j+2])) {
+			paramsEnd = j
+			break
+		}
+	}
+	if paramsEnd == i {
+		return len(data)
+	}
+	paramOps, trailing := scanContentOps(data[i:paramsEnd])
+	for _, op := range paramOps {
+		(*ops)[idx].Operands = append((*ops)[idx].Operands, op.Operands...)
+		(*ops)[idx].Operands = append((*ops)[idx].Operands, op.Operator)
+	}
+	(*ops)[idx].Operands = append((*ops)[idx].Operands, trailing...)
+
+	// Dados binários até EI precedido e seguido de espaço
+	for j := paramsEnd + 3; j+2 <= len(data); j++ {
+		if data[j] == 'E' && data[j+1] == 'I' && isContentWhitespace(data[j-1]) &&
+			(j+2 == len(data) || isContentWhitespace(data[j+2])) {
+			*ops = append(*ops, contentOp{Operator: "EI"})
+			return j + 2
+		}
+	}
+	return len(data)
+}
+
+// walkContent calls visit for each operator of a content stream and of the form XObjects it
+// paints, with the resources in effect. A form is walked right after its Do operator, between
+// a q and a Q added by the walk, since painting a form saves and restores the graphics state.
+// visit returns false to stop the walk.
+func (pa *PDFAnalyzer) walkContent(ctx *model.Context, ops []contentOp, resources types.Dict, visit func(op contentOp, resources types.Dict) bool) {
+	pa.walkContentOps(ctx, ops, resources, visit, 0)
+}
+
+// walkContentOps walks content at the given form nesting depth and reports whether visit let
+// the walk run to the end
+func (pa *PDFAnalyzer) walkContentOps(ctx *model.Context, ops []contentOp, resources types.Dict, visit func(op contentOp, resources types.Dict) bool, depth int) bool {
+	for _, op := range ops {
+		if !visit(op, resources) {
+			return false
+		}
+		if op.Operator != "Do" || len(op.Operands) == 0 || depth >= maxFormDepth {
+			continue
+		}
+		xobj, _, err := ctx.DereferenceStreamDict(pa.namedResource(ctx, resources, "XObject", op.Operands[0]))
+		if err != nil || xobj == nil {
+			continue
+		}
+		if subtype := xobj.Dict.NameEntry("Subtype"); subtype == nil || *subtype != "Form" || xobj.Decode() != nil {
+			continue
+		}
+		// Formulários sem /Resources usam os da página
+		formResources := pa.resourcesDict(ctx, xobj.Dict)
+		if formResources == nil {
+			formResources = resources
+		}
+		if !visit(contentOp{Operator: "q"}, formResources) ||
+			!pa.walkContentOps(ctx, parseContentOps(xobj.Content), formResources, visit, depth+1) ||
+			!visit(contentOp{Operator: "Q"}, formResources) {
+			return false
+		}
+	}
+	return true
+}
+
+// pageContentOps reads and parses the content of a page; nil when it has none
+func (pa *PDFAnalyzer) pageContentOps(ctx *model.Context, pageDict types.Dict, pageNr int) []contentOp {
+	content, err := ctx.PageContent(pageDict, pageNr)
+	if err != nil {
+		return nil
+	}
+	return parseContentOps(content)
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// pdfDate is a parsed Info or XMP date. HasZone is false when the source carried no timezone,
+// in which case Time is expressed in UTC but the actual instant is ambiguous.
+type pdfDate struct {
+	Time    time.Time
+	HasZone bool
+	Offset  int // UTC offset in seconds
+}
+
+// Info dictionary dates: D:YYYYMMDDHHmmSSOHH'mm' (every part after the year is optional)
+var pdfDatePattern = regexp.MustCompile(`^(?:D:)?(\d{4})(\d{2})?(\d{2})?(\d{2})?(\d{2})?(\d{2})?(?:(Z|[+-])(?:(\d{2})'?(?:(\d{2})'?)?)?)?`)
+
+// XMP dates: ISO 8601 subset, YYYY[-MM[-DD[THH:mm[:SS[.s]][TZD]]]]
+var xmpDatePattern = regexp.MustCompile(`^(\d{4})(?:-(\d{2}))?(?:-(\d{2}))?(?:T(\d{2}):(\d{2})(?::(\d{2})(?:\.\d+)?)?)?(Z|[+-]\d{2}:?\d{2})?$`)
+
+// parsePDFDate parses a date as written in the Info dictionary ("D:20230115103000+02'00'"),
+// or in XMP when the field was taken from there. Truncated dates are accepted (missing parts
+// default to the start of the period). The result carries the date's own UTC offset; dates
+// without a timezone are taken as UTC.
+func parsePDFDate(s string) (time.Time, error) {
+	d, ok := parseInfoDate(s)
+	if !ok {
+		d, ok = parseXMPDate(s)
+	}
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid PDF date %q", s)
+	}
+	return d.Time.In(time.FixedZone("", d.Offset)), nil
+}
+
+// errInvalidDate reports date components out of range (month 13, hour 25, ...)
+var errInvalidDate = errors.New("date component out of range")
+
+// parseInfoDate parses an Info dictionary date string
+func parseInfoDate(s string) (pdfDate, bool) {
+	m := pdfDatePattern.FindStringSubmatch(s)
+	if m == nil {
+		return pdfDate{}, false
+	}
+	t, err := dateFromParts(m[1], m[2], m[3], m[4], m[5], m[6])
+	if err != nil {
+		return pdfDate{}, false
+	}
+
+	switch m[7] {
+	case "":
+		return pdfDate{Time: t}, true
+	case "Z":
+		return pdfDate{Time: t, HasZone: true}, true
+	}
+	offset := (atoi(m[8])*60 + atoi(m[9])) * 60
+	if m[7] == "-" {
+		offset = -offset
+	}
+	return pdfDate{Time: t.Add(-time.Duration(offset) * time.Second), HasZone: true, Offset: offset}, true
+}
+
+// parseXMPDate parses an XMP (ISO 8601) date string
+func parseXMPDate(s string) (pdfDate, bool) {
+	m := xmpDatePattern.FindStringSubmatch(s)
+	if m == nil {
+		return pdfDate{}, false
+	}
+	t, err := dateFromParts(m[1], m[2], m[3], m[4], m[5], m[6])
+	if err != nil {
+		return pdfDate{}, false
+	}
+
+	zone := m[7]
+	switch {
+	case zone == "":
+		return pdfDate{Time: t}, true
+	case zone == "Z":
+		return pdfDate{Time: t, HasZone: true}, true
+	}
+	offset := (atoi(zone[1:3])*60 + atoi(zone[len(zone)-2:])) * 60
+	if zone[0] == '-' {
+		offset = -offset
+	}
+	return pdfDate{Time: t.Add(-time.Duration(offset) * time.Second), HasZone: true, Offset: offset}, true
+}
+
+// dateFromParts builds a UTC time from date components; missing month and day default to 1
+func dateFromParts(year, month, day, hour, minute, second string) (time.Time, error) {
+	mo, d := 1, 1
+	if month != "" {
+		mo = atoi(month)
+	}
+	if day != "" {
+		d = atoi(day)
+	}
+	h, mi, sec := atoi(hour), atoi(minute), atoi(second)
+	if mo < 1 || mo > 12 || d < 1 || d > 31 || h > 23 || mi > 59 || sec > 59 {
+		return time.Time{}, errInvalidDate
+	}
+	return time.Date(atoi(year), time.Month(mo), d, h, mi, sec, 0, time.UTC), nil
+}
+
+// parseDocumentDates fills the parsed creation and modification dates
+func parseDocumentDates(info *PDFInfo) {
+	if t, err := parsePDFDate(info.CreationDate); err == nil {
+		info.CreationDateParsed = t
+	}
+	if t, err := parsePDFDate(info.ModDate); err == nil {
+		info.ModDateParsed = t
+	}
+}
+
+// formatPDFDate formats a parsed document date for the report, keeping its UTC offset
+func formatPDFDate(t time.Time) string {
+	return t.Format("2006-01-02 15:04:05 -07:00")
+}
+
+// checkDateTimezones compares the Info and XMP dates. A pair written with different offsets
+// that doesn't represent the same instant usually means the generator converted one of them
+// wrongly; dates without any timezone are ambiguous on their own.
+func checkDateTimezones(info *PDFInfo) {
+	info.DateTimezoneIssues = nil
+
+	pairs := []struct {
+		label    string
+		infoDate string
+		xmpKey   string
+	}{
+		{"creation date", info.CreationDate, "xmp:CreateDate"},
+		{"modification date", info.ModDate, "xmp:ModifyDate"},
+	}
+
+	for _, p := range pairs {
+		infoDate, infoOK := parseInfoDate(p.infoDate)
+		xmpDate, xmpOK := parseXMPDate(info.XMPProperties[p.xmpKey])
+
+		if infoOK && !infoDate.HasZone {
+			info.DateTimezoneIssues = append(info.DateTimezoneIssues,
+				fmt.Sprintf("Info %s has no timezone", p.label))
+		}
+		if xmpOK && !xmpDate.HasZone {
+			info.DateTimezoneIssues = append(info.DateTimezoneIssues,
+				fmt.Sprintf("XMP %s has no timezone", p.label))
+		}
+
+		// Fusos diferentes só são problema quando os instantes também diferem
+		if infoOK && xmpOK && infoDate.HasZone && xmpDate.HasZone &&
+			infoDate.Offset != xmpDate.Offset && !infoDate.Time.Equal(xmpDate.Time) {
+			info.DateTimezoneIssues = append(info.DateTimezoneIssues,
+				fmt.Sprintf("Info and XMP %s use different timezones (UTC%s vs UTC%s) and differ by %s",
+					p.label, formatOffset(infoDate.Offset), formatOffset(xmpDate.Offset),
+					formatDuration(xmpDate.Time.Sub(infoDate.Time))))
+		}
+	}
+
+	info.DateTimezoneConsistent = len(info.DateTimezoneIssues) == 0
+}
+
+// formatOffset formats a UTC offset in seconds as "+02:00"
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d:%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// formatDuration formats a signed duration as hours and minutes, e.g. "-3h00m"
+func formatDuration(d time.Duration) string {
+	sign := "+"
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	return fmt.Sprintf("%s%dh%02dm", sign, int(d.Hours()), int(d.Minutes())%60)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Thresholds of the document class heuristics
+const (
+	scannedTextPerPage = 100  // fewer extracted characters per page than this suggests scanned images
+	scannedImagesRatio = 0.9  // images per page from which a text-less document is taken as scanned
+	bookMinPages       = 40   // documents this long are books or reports
+	paperSizeTolerance = 4.0  // points of slack when matching standard paper sizes
+	aspectTolerance    = 0.04 // slack when matching slide aspect ratios
+)
+
+// Standard paper sizes in points, portrait
+var paperSizes = []struct {
+	Name          string
+	Width, Height float64
+}{
+	{"A3", 842, 1191},
+	{"A4", 595, 842},
+	{"A5", 420, 595},
+	{"US Letter", 612, 792},
+	{"US Legal", 612, 1008},
+	{"Tabloid", 792, 1224},
+}
+
+// paperSizeName returns the name of the standard paper size matching the dimensions in
+// either orientation, or ""
+func paperSizeName(width, height float64) string {
+	w, h := math.Min(width, height), math.Max(width, height)
+	for _, p := range paperSizes {
+		if math.Abs(w-p.Width) <= paperSizeTolerance && math.Abs(h-p.Height) <= paperSizeTolerance {
+			return p.Name
+		}
+	}
+	return ""
+}
+
+// pageSizeLabel names the paper size of a page with its orientation, given its size as
+// displayed (see effectiveSize), e.g. "A4 portrait", or "Custom" for non-standard sizes
+func pageSizeLabel(width, height float64) string {
+	name := paperSizeName(width, height)
+	if name == "" {
+		return "Custom"
+	}
+	switch {
+	case width > height:
+		return name + " landscape"
+	case width < height:
+		return name + " portrait"
+	}
+	return name
+}
+
+// slideAspectName returns "16:9" or "4:3" for landscape pages with a typical slide aspect
+// ratio, or ""
+func slideAspectName(width, height float64) string {
+	if height <= 0 || width <= height {
+		return ""
+	}
+	ratio := width / height
+	switch {
+	case math.Abs(ratio-16.0/9.0) <= aspectTolerance:
+		return "16:9"
+	case math.Abs(ratio-16.0/10.0) <= aspectTolerance:
+		return "16:10"
+	case math.Abs(ratio-4.0/3.0) <= aspectTolerance:
+		return "4:3"
+	}
+	return ""
+}
+
+// dominantPageSize returns the most common page size as displayed
+func dominantPageSize(pages []PageInfo) (width, height float64) {
+	counts := make(map[[2]float64]int)
+	best := 0
+	for _, p := range pages {
+		size := [2]float64{math.Round(p.EffectiveWidth), math.Round(p.EffectiveHeight)}
+		counts[size]++
+		if counts[size] > best {
+			best = counts[size]
+			width, height = size[0], size[1]
+		}
+	}
+	return width, height
+}
+
+// imagesPerPage returns the number of images per page
+func imagesPerPage(info *PDFInfo) float64 {
+	if info.PageCount == 0 {
+		return 0
+	}
+	return float64(info.ImagesCount) / float64(info.PageCount)
+}
+
+// isProbablyScanned reports whether the document looks like scanned pages without a text layer
+// (it needs OCR): next to no extractable text, and about one image or more per page
+func isProbablyScanned(info *PDFInfo) bool {
+	// Sem extração de texto confiável não dá para distinguir digitalizações
+	return info.PageCount > 0 && info.Completeness.Text == completenessFull &&
+		info.TotalTextLength < scannedTextPerPage*info.PageCount &&
+		imagesPerPage(info) >= scannedImagesRatio
+}
+
+// documentClass combines page size, page count, text density and form/signature presence into
+// a best-guess description, e.g. "single-page A4 form" or "slide deck in landscape 16:9".
+// It returns "" when there is too little information.
+func documentClass(info *PDFInfo) string {
+	if info.PageCount == 0 || len(info.Pages) == 0 {
+		return ""
+	}
+	width, height := dominantPageSize(info.Pages)
+	if width == 0 || height == 0 {
+		return ""
+	}
+
+	pages := "multi-page"
+	if info.PageCount == 1 {
+		pages = "single-page"
+	}
+	paper := ""
+	if name := paperSizeName(width, height); name != "" {
+		paper = name + " "
+	}
+	scanned := info.IsProbablyScanned
+
+	switch {
+	case slideAspectName(width, height) != "" && paper == "":
+		return fmt.Sprintf("slide deck in landscape %s", slideAspectName(width, height))
+	case info.HasForms && info.FilledFieldCount+info.EmptyFieldCount > info.SignatureCount+info.DocumentTimestampCount:
+		return fmt.Sprintf("%s %sform", pages, paper)
+	case scanned && info.HasDigitalSignatures:
+		return fmt.Sprintf("%s scanned contract", pages)
+	case scanned:
+		return fmt.Sprintf("%s scanned %sdocument", pages, paper)
+	case info.HasDigitalSignatures:
+		return fmt.Sprintf("%s signed %sdocument", pages, paper)
+	case info.PageCount >= bookMinPages:
+		return "book/report"
+	case width > height:
+		return fmt.Sprintf("%s landscape %sdocument", pages, paper)
+	}
+	return fmt.Sprintf("%s %sdocument", pages, paper)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// fallbackIDPattern matches a trailer /ID written as two hex strings, the usual form
+var fallbackIDPattern = regexp.MustCompile(`/ID\s*\[\s*<([0-9A-Fa-f\s]*)>\s*<([0-9A-Fa-f\s]*)>\s*\]`)
+
+// fileIdentifierBytes decodes one element of the trailer /ID array, a literal or hex string
+func fileIdentifierBytes(obj types.Object) []byte {
+	switch s := obj.(type) {
+	case types.HexLiteral:
+		b, err := s.Bytes()
+		if err == nil {
+			return b
+		}
+	case types.StringLiteral:
+		b, err := types.Unescape(string(s))
+		if err == nil {
+			return b
+		}
+	}
+	return nil
+}
+
+// setFileIdentifier stores the two /ID elements hex-encoded. Writers keep the first one for the
+// life of the document and replace the second on every save, so when they differ the file was
+// modified after it was created.
+func setFileIdentifier(info *PDFInfo, first, second []byte) {
+	if len(first) == 0 {
+		return
+	}
+	info.DocumentID = hex.EncodeToString(first)
+	info.RevisionID = hex.EncodeToString(second)
+	info.IDChanged = len(second) > 0 && !bytes.Equal(first, second)
+}
+
+// extractFileIdentifier reads the file identifier (/ID) from the trailer
+func (pa *PDFAnalyzer) extractFileIdentifier(ctx *model.Context, info *PDFInfo) {
+	if ctx.XRefTable == nil || len(ctx.XRefTable.ID) < 2 {
+		return
+	}
+	var ids [2][]byte
+	for i := range ids {
+		obj, err := ctx.Dereference(ctx.XRefTable.ID[i])
+		if err != nil {
+			return
+		}
+		ids[i] = fileIdentifierBytes(obj)
+	}
+	setFileIdentifier(info, ids[0], ids[1])
+}
+
+// fallbackFileIdentifier reads the /ID of a trailer found by byte scanning
+func fallbackFileIdentifier(trailer string, info *PDFInfo) {
+	m := fallbackIDPattern.FindStringSubmatch(trailer)
+	if m == nil {
+		return
+	}
+	var ids [2][]byte
+	for i := range ids {
+		digits := strings.Join(strings.Fields(m[i+1]), "")
+		// Um dígito final sem par vale como seguido de 0
+		if len(digits)%2 == 1 {
+			digits += "0"
+		}
+		b, err := hex.DecodeString(digits)
+		if err != nil {
+			return
+		}
+		ids[i] = b
+	}
+	setFileIdentifier(info, ids[0], ids[1])
+}
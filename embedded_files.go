@@ -0,0 +1,164 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// embeddedFile is a file embedded in the document through the /EmbeddedFiles name tree
+type embeddedFile struct {
+	Name    string
+	Subtype string // MIME type from the embedded file stream's /Subtype, if any
+	Data    []byte // decoded contents; nil when the stream could not be decoded
+}
+
+// embeddedFiles walks the /Names /EmbeddedFiles name tree and decodes each embedded file stream
+func (pa *PDFAnalyzer) embeddedFiles(ctx *model.Context) []embeddedFile {
+	namesObj, found := ctx.RootDict.Find("Names")
+	if !found || namesObj == nil {
+		return nil
+	}
+	names, err := ctx.DereferenceDict(namesObj)
+	if err != nil || names == nil {
+		return nil
+	}
+	treeObj, found := names.Find("EmbeddedFiles")
+	if !found || treeObj == nil {
+		return nil
+	}
+
+	var files []embeddedFile
+	visited := make(map[int]bool)
+	var walk func(obj types.Object, depth int)
+	walk = func(obj types.Object, depth int) {
+		if depth > 32 {
+			return
+		}
+		if ref, ok := obj.(types.IndirectRef); ok {
+			if visited[int(ref.ObjectNumber)] {
+				return
+			}
+			visited[int(ref.ObjectNumber)] = true
+		}
+		node, err := ctx.DereferenceDict(obj)
+		if err != nil || node == nil {
+			return
+		}
+
+		// Folhas: pares [nome especificação-de-arquivo]
+		if namesArr, found := node.Find("Names"); found {
+			if arr, err := ctx.DereferenceArray(namesArr); err == nil {
+				for i := 0; i+1 < len(arr); i += 2 {
+					key := getStringFromDict(types.Dict{"k": arr[i]}, "k")
+					if file, ok := pa.readFileSpec(ctx, arr[i+1], key); ok {
+						files = append(files, file)
+					}
+				}
+			}
+		}
+		if kidsObj, found := node.Find("Kids"); found {
+			if kids, err := ctx.DereferenceArray(kidsObj); err == nil {
+				for _, kid := range kids {
+					walk(kid, depth+1)
+				}
+			}
+		}
+	}
+	walk(treeObj, 0)
+	return files
+}
+
+// readFileSpec reads the name and embedded stream of a file specification dictionary
+func (pa *PDFAnalyzer) readFileSpec(ctx *model.Context, obj types.Object, fallbackName string) (embeddedFile, bool) {
+	spec, err := ctx.DereferenceDict(obj)
+	if err != nil || spec == nil {
+		return embeddedFile{}, false
+	}
+
+	file := embeddedFile{Name: getStringFromDict(spec, "UF")}
+	if file.Name == "" {
+		file.Name = getStringFromDict(spec, "F")
+	}
+	if file.Name == "" {
+		file.Name = fallbackName
+	}
+
+	efObj, found := spec.Find("EF")
+	if !found || efObj == nil {
+		return file, true
+	}
+	ef, err := ctx.DereferenceDict(efObj)
+	if err != nil || ef == nil {
+		return file, true
+	}
+	streamObj, found := ef.Find("UF")
+	if !found {
+		streamObj, found = ef.Find("F")
+	}
+	if !found || streamObj == nil {
+		return file, true
+	}
+	sd, _, err := ctx.DereferenceStreamDict(streamObj)
+	if err != nil || sd == nil {
+		return file, true
+	}
+	if subtype := sd.Dict.NameEntry("Subtype"); subtype != nil {
+		// O tipo MIME vem codificado no nome, e.g. /application#2Fpdf
+		file.Subtype = *subtype
+		if decoded, err := types.DecodeName(*subtype); err == nil {
+			file.Subtype = decoded
+		}
+	}
+	if err := sd.Decode(); err == nil {
+		file.Data = sd.Content
+	}
+	return file, true
+}
+
+// safeAttachmentName reduces an attachment name to a plain file name, so that names such as
+// "../../etc/passwd" or "C:\dir\file" cannot escape the target directory
+func safeAttachmentName(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = path.Base(name)
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == ':' {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "." || name == ".." || name == "/" {
+		return ""
+	}
+	return name
+}
+
+// writeAttachment saves an embedded file into dir. Existing files are never overwritten.
+func writeAttachment(dir string, file embeddedFile) error {
+	name := safeAttachmentName(file.Name)
+	if name == "" {
+		return fmt.Errorf("attachment %q has no usable file name", file.Name)
+	}
+	if file.Data == nil {
+		return fmt.Errorf("attachment %q could not be decoded", file.Name)
+	}
+	target := filepath.Join(dir, name)
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("%s already exists, not overwriting", target)
+		}
+		return err
+	}
+	if _, err := f.Write(file.Data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
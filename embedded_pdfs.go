@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"os"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// maxEmbeddedDepth limits how deep --recurse-embedded follows PDFs embedded in PDFs
+const maxEmbeddedDepth = 5
+
+// isPDFData reports whether data starts (within its first KB) with a PDF header
+func isPDFData(data []byte) bool {
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	return bytes.Contains(head, []byte("%PDF-"))
+}
+
+// analyzeEmbeddedPDFs runs the full analysis on each PDF embedded in the file, nesting the
+// results under the parent. Cycles (a PDF embedding itself, directly or not) are detected
+// by comparing the SHA256 of each embedded PDF with those of the files embedding it, and the
+// nesting depth is limited. Only the chain of ancestors counts: the same PDF attached to
+// several files of a batch, or twice to one file, is analyzed each time. ctx is the file's
+// context, already read by analyzePDFCPU.
+func (pa *PDFAnalyzer) analyzeEmbeddedPDFs(ctx *model.Context, data []byte, info *PDFInfo) {
+	// Sem --hash sha256 o hash do arquivo não está em info.Hashes
+	fileHash := info.Hashes["sha256"]
+	if fileHash == "" {
+		fileHash = fmt.Sprintf("%x", sha256.Sum256(data))
+	}
+	ancestors := make(map[string]bool, len(pa.ancestorPDFs)+1)
+	for hash := range pa.ancestorPDFs {
+		ancestors[hash] = true
+	}
+	ancestors[fileHash] = true
+
+	for _, file := range pa.embeddedFiles(ctx) {
+		if file.Data == nil || !isPDFData(file.Data) {
+			continue
+		}
+		embedded := EmbeddedPDFInfo{Name: file.Name}
+		hash := fmt.Sprintf("%x", sha256.Sum256(file.Data))
+
+		switch {
+		case ancestors[hash]:
+			embedded.SkipReason = "embedding cycle (the PDF contains a file that embeds it)"
+		case pa.embeddedDepth+1 > maxEmbeddedDepth:
+			embedded.SkipReason = fmt.Sprintf("nesting deeper than %d levels", maxEmbeddedDepth)
+		default:
+			child, err := pa.analyzeEmbeddedData(file, info, ancestors)
+			if err != nil {
+				embedded.SkipReason = err.Error()
+			} else {
+				embedded.Info = child
+			}
+		}
+		info.EmbeddedPDFs = append(info.EmbeddedPDFs, embedded)
+	}
+}
+
+// analyzeEmbeddedData analyzes an embedded PDF through a temporary file, since the
+// analyses work on file paths. ancestors holds the SHA256 of the parent and the files
+// embedding it.
+func (pa *PDFAnalyzer) analyzeEmbeddedData(file embeddedFile, parent *PDFInfo, ancestors map[string]bool) (*PDFInfo, error) {
+	tmp, err := os.CreateTemp("", "pdf-info-embedded-*.pdf")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(file.Data); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+
+	child := *pa
+	child.embeddedDepth = pa.embeddedDepth + 1
+	child.ancestorPDFs = ancestors
+	child.Password = ""
+	child.ExtractAttachmentsDir = ""
+	info, err := child.AnalyzePDF(tmp.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	// Identificar o arquivo pelo nome do anexo, não pelo arquivo temporário
+	info.FileName = file.Name
+	info.FilePath = parent.FilePath + "!" + file.Name
+	info.LastModified = parent.LastModified
+	return info, nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// weakKeyBits is the key length below which RC4 encryption is considered broken
+const weakKeyBits = 40
+
+// boundPermissionsRevision is the first standard handler revision whose /Perms entry binds
+// the permission flags to the encryption key, so that altering /P is detected
+const boundPermissionsRevision = 5
+
+// Fallback patterns for the encrypt dictionary algorithm entries
+var (
+	fallbackVPattern      = regexp.MustCompile(`/V\s+(\d+)`)
+	fallbackRPattern      = regexp.MustCompile(`/R\s+(\d+)`)
+	fallbackLengthPattern = regexp.MustCompile(`/Length\s+(\d+)`)
+	fallbackCFMPattern    = regexp.MustCompile(`/CFM\s*/(\w+)`)
+)
+
+// encryptionAlgorithm names the algorithm and key length selected by the encrypt dictionary
+// /V (algorithm version), /Length (RC4 key length in bits) and the /CFM method of the crypt
+// filter used for streams (V 4 and 5). It returns "" when the combination is not recognized.
+func encryptionAlgorithm(v, length int, cfm string) (string, int) {
+	// Alguns geradores escrevem /Length em bytes no filtro de criptografia
+	if length > 0 && length < weakKeyBits {
+		length *= 8
+	}
+	switch v {
+	case 1:
+		return "RC4", 40
+	case 2:
+		if length == 0 {
+			length = 40
+		}
+		return "RC4", length
+	case 3:
+		return "proprietary (V 3)", length
+	case 4, 5:
+		switch cfm {
+		case "V2":
+			if length == 0 {
+				length = 128
+			}
+			return "RC4", length
+		case "AESV2":
+			return "AES", 128
+		case "AESV3":
+			return "AES", 256
+		case "None":
+			return "none (identity crypt filter)", 0
+		}
+	}
+	return "", 0
+}
+
+// setEncryptionAlgorithm records the algorithm of the encrypt dictionary entries
+func setEncryptionAlgorithm(info *PDFInfo, v, r, length int, cfm string) {
+	info.EncryptionAlgorithm, info.EncryptionKeyBits = encryptionAlgorithm(v, length, cfm)
+	info.EncryptionRevision = r
+}
+
+// encryptionDescription formats the algorithm for the report, e.g. "AES-256 (R6)" or
+// "RC4 40-bit (R2)"
+func encryptionDescription(info *PDFInfo) string {
+	var desc string
+	switch {
+	case info.EncryptionAlgorithm == "":
+		return ""
+	case info.EncryptionAlgorithm == "AES":
+		desc = fmt.Sprintf("AES-%d", info.EncryptionKeyBits)
+	case info.EncryptionKeyBits > 0:
+		desc = fmt.Sprintf("%s %d-bit", info.EncryptionAlgorithm, info.EncryptionKeyBits)
+	default:
+		desc = info.EncryptionAlgorithm
+	}
+	if info.EncryptionRevision > 0 {
+		desc += fmt.Sprintf(" (R%d)", info.EncryptionRevision)
+	}
+	return desc
+}
+
+// isWeakEncryption reports whether the document uses 40-bit RC4, which can be brute-forced
+func isWeakEncryption(info *PDFInfo) bool {
+	return info.EncryptionAlgorithm == "RC4" && info.EncryptionKeyBits <= weakKeyBits
+}
+
+// permissionsEnforced reports whether the permission flags are cryptographically protected
+// against tampering: only the standard handler from revision 5 (AES-256) does so. With older
+// revisions /P can be edited without the password.
+func permissionsEnforced(info *PDFInfo, encDict types.Dict) bool {
+	if !isStandardSecurityHandler(info) || info.EncryptionRevision < boundPermissionsRevision {
+		return false
+	}
+	_, hasPerms := encDict.Find("Perms")
+	return hasPerms
+}
+
+// securityWarnings lists deprecated or weak protection: RC4, 40-bit keys, the withdrawn AES-256
+// revision 5, and permission restrictions on a document that opens without a password (any
+// tool can ignore them, since the restrictions are only honored by compliant viewers)
+func securityWarnings(info *PDFInfo, openedWithoutPassword bool) []string {
+	var warnings []string
+	if info.EncryptionAlgorithm == "RC4" {
+		warnings = append(warnings, "RC4 encryption is deprecated (removed in PDF 2.0); use AES")
+	}
+	if isWeakEncryption(info) {
+		warnings = append(warnings, "40-bit key: the encryption can be broken in minutes")
+	}
+	if info.EncryptionAlgorithm == "AES" && info.EncryptionRevision == 5 {
+		warnings = append(warnings, "AES-256 revision 5 is deprecated: its password check has a known weakness; use revision 6")
+	}
+	if openedWithoutPassword && info.OwnerPasswordSet && isStandardSecurityHandler(info) && hasPermissionRestrictions(info) {
+		warnings = append(warnings, "permissions are restricted by an owner password only: the document opens without a password, so the restrictions are trivially bypassed")
+	}
+	return warnings
+}
+
+// hasPermissionRestrictions reports whether any permission is denied
+func hasPermissionRestrictions(info *PDFInfo) bool {
+	return !(info.PrintAllowed && info.ModifyAllowed && info.CopyAllowed && info.AddNotesAllowed &&
+		info.FillFormsAllowed && info.AccessibilityAllowed && info.AssembleAllowed && info.PrintHighQualityAllowed)
+}
+
+// analyzeEncryptionAlgorithm reads the algorithm entries of the encrypt dictionary
+func (pa *PDFAnalyzer) analyzeEncryptionAlgorithm(ctx *model.Context, encDict types.Dict, info *PDFInfo) {
+	var v, r, length int
+	if n := encDict.IntEntry("V"); n != nil {
+		v = *n
+	}
+	if n := encDict.IntEntry("R"); n != nil {
+		r = *n
+	}
+	if n := encDict.IntEntry("Length"); n != nil {
+		length = *n
+	}
+
+	// V 4 e 5: o método vem do filtro nomeado em /StmF, dentro de /CF
+	cfm := ""
+	if v >= 4 {
+		filterName := "StdCF"
+		if name := encDict.NameEntry("StmF"); name != nil {
+			filterName = *name
+		}
+		if filterName == "Identity" {
+			cfm = "None"
+		} else if cfObj, found := encDict.Find("CF"); found && cfObj != nil {
+			if cf, err := ctx.DereferenceDict(cfObj); err == nil && cf != nil {
+				if filterObj, found := cf.Find(filterName); found && filterObj != nil {
+					if filter, err := ctx.DereferenceDict(filterObj); err == nil && filter != nil {
+						if name := filter.NameEntry("CFM"); name != nil {
+							cfm = *name
+						}
+						if n := filter.IntEntry("Length"); n != nil {
+							length = *n
+						}
+					}
+				}
+			}
+		}
+	}
+	setEncryptionAlgorithm(info, v, r, length, cfm)
+}
+
+// fallbackEncryptionAlgorithm reads the algorithm entries from the encrypt dictionary source,
+// for files pdfcpu could not open. The crypt filter dictionary is usually written inline.
+func fallbackEncryptionAlgorithm(body string, info *PDFInfo) {
+	var v, r, length int
+	if m := fallbackVPattern.FindStringSubmatch(body); m != nil {
+		v = atoi(m[1])
+	}
+	if m := fallbackRPattern.FindStringSubmatch(body); m != nil {
+		r = atoi(m[1])
+	}
+	if m := fallbackLengthPattern.FindStringSubmatch(body); m != nil {
+		length = atoi(m[1])
+	}
+	cfm := ""
+	if m := fallbackCFMPattern.FindStringSubmatch(body); m != nil {
+		cfm = m[1]
+	}
+	setEncryptionAlgorithm(info, v, r, length, cfm)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"math"
+	"sort"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Thresholds of the stream entropy analysis
+const (
+	minEntropySample   = 256 // streams shorter than this give unreliable entropy values
+	highEntropy        = 7.5 // bits per byte; random or encrypted data approaches 8
+	maxEntropyExamples = 5   // highest-entropy streams kept for the report
+)
+
+// StreamEntropy is the Shannon entropy of one stream's decoded content
+type StreamEntropy struct {
+	ObjectNumber int
+	Kind         string // stream /Type or /Subtype, e.g. "EmbeddedFile", "Image"
+	Size         int    // decoded length in bytes
+	Entropy      float64
+}
+
+// Filters whose output is itself compressed: high entropy after decoding them is expected
+var compressedImageFilters = map[string]bool{
+	"DCTDecode":      true,
+	"JPXDecode":      true,
+	"JBIG2Decode":    true,
+	"CCITTFaxDecode": true,
+}
+
+// shannonEntropy returns the entropy of data in bits per byte (0 to 8)
+func shannonEntropy(data []byte) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	var counts [256]int
+	for _, b := range data {
+		counts[b]++
+	}
+	entropy := 0.0
+	total := float64(len(data))
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+		p := float64(c) / total
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// streamKind describes a stream by its /Type or /Subtype
+func streamKind(d types.Dict) string {
+	if subtype := d.NameEntry("Subtype"); subtype != nil {
+		return *subtype
+	}
+	if typ := d.NameEntry("Type"); typ != nil {
+		return *typ
+	}
+	return "stream"
+}
+
+// analyzeStreamEntropy computes the entropy of every decodable stream and flags those close
+// to 8 bits/byte: once the standard filters are undone, content this random is usually an
+// encrypted payload or an archive hidden in the file. Streams compressed with an image codec
+// are skipped, since their decoded form is still compressed.
+func (pa *PDFAnalyzer) analyzeStreamEntropy(ctx *model.Context, info *PDFInfo) {
+	var all []StreamEntropy
+	pa.forEachObject(ctx, func(objNr int, obj types.Object) {
+		sd, ok := obj.(types.StreamDict)
+		if !ok {
+			return
+		}
+		for _, f := range pa.streamFilterChain(ctx, sd.Dict) {
+			if compressedImageFilters[f] {
+				return
+			}
+		}
+		if err := sd.Decode(); err != nil || len(sd.Content) < minEntropySample {
+			return
+		}
+
+		se := StreamEntropy{
+			ObjectNumber: objNr,
+			Kind:         streamKind(sd.Dict),
+			Size:         len(sd.Content),
+			Entropy:      math.Round(shannonEntropy(sd.Content)*100) / 100,
+		}
+		all = append(all, se)
+		if se.Entropy >= highEntropy {
+			info.HighEntropyStreams = append(info.HighEntropyStreams, se)
+		}
+	})
+
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Entropy > all[j].Entropy })
+	if len(all) > maxEntropyExamples {
+		all = all[:maxEntropyExamples]
+	}
+	info.HighestEntropyStreams = all
+}
@@ -0,0 +1,63 @@
+package main
+
+// Exit statuses, from the most to the least severe (see --help)
+const (
+	exitClean            = 0 // every file was analyzed and nothing is wrong with it
+	exitFailure          = 1 // a file could not be read, or an --assert/--fail-on check failed
+	exitInvalidSignature = 2 // a signature failed validation
+	exitIncomplete       = 3 // an encrypted or restricted file could not be fully analyzed
+)
+
+// exitCodesHelp documents the exit statuses in the --help output
+const exitCodesHelp = `Exit status:
+  0  the PDF was analyzed and nothing is wrong with it
+  1  a file could not be read, or an --assert/--fail-on check failed
+  2  a digital signature is invalid (with --verify-only, also when a signer
+     certificate chain is not trusted or the signed bytes do not match)
+  3  the PDF is encrypted or restricted and could not be fully analyzed
+With several files, the most severe status is returned (1, then 2, then 3).
+`
+
+// outcomeExitCode maps the result of a successful analysis to an exit status
+func outcomeExitCode(info *PDFInfo) int {
+	for _, sig := range info.Signatures {
+		if sig.Status == "Invalid" {
+			return exitInvalidSignature
+		}
+	}
+	if info.IsEncrypted && len(info.Completeness.incompleteFeatures()) > 0 {
+		return exitIncomplete
+	}
+	return exitClean
+}
+
+// verifyExitCode maps the result of an analysis to an exit status for --verify-only: every
+// signature must be valid, match its signed bytes and chain to a trusted root
+func verifyExitCode(info *PDFInfo) int {
+	for _, sig := range info.Signatures {
+		if sig.Status == "Invalid" || sig.ByteRangeDigest == digestMismatch {
+			return exitInvalidSignature
+		}
+		// Carimbos de tempo do documento não têm certificado de signatário
+		if !sig.IsDocumentTimestamp && !sig.ChainTrusted {
+			return exitInvalidSignature
+		}
+	}
+	return outcomeExitCode(info)
+}
+
+// exitSeverity orders exit statuses by severity for combining the results of several files
+var exitSeverity = map[int]int{
+	exitClean:            0,
+	exitIncomplete:       1,
+	exitInvalidSignature: 2,
+	exitFailure:          3,
+}
+
+// worseExitCode returns the more severe of two exit statuses
+func worseExitCode(a, b int) int {
+	if exitSeverity[b] > exitSeverity[a] {
+		return b
+	}
+	return a
+}
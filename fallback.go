@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+var (
+	fallbackObjPattern     = regexp.MustCompile(`(?s)(\d+)\s+(\d+)\s+obj\b(.*?)\bendobj`)
+	fallbackStreamPattern  = regexp.MustCompile(`(?s)^(.*?)\bstream\r?\n(.*)\bendstream`)
+	fallbackTrailerPattern = regexp.MustCompile(`(?s)trailer\s*(<<.*?>>)\s*startxref`)
+	fallbackVersionPattern = regexp.MustCompile(`%PDF-(\d\.\d)`)
+	fallbackPageTypeRegexp = regexp.MustCompile(`/Type\s*/Page\b`)
+	fallbackCountPattern   = regexp.MustCompile(`/Count\s+(\d+)`)
+	fallbackFirstPattern   = regexp.MustCompile(`/First\s+(\d+)`)
+
+	// Uma expressão por chave custaria uma compilação por chamada: a chave é capturada
+	fallbackRefPattern         = regexp.MustCompile(`/(\w+)\s+(\d+)\s+\d+\s+R`)
+	fallbackStringStartPattern = regexp.MustCompile(`/(\w+)\s*[(<]`)
+)
+
+// fallbackRef returns the object number of the first "/key N G R" in dictionary source text
+func fallbackRef(body, key string) (int, bool) {
+	for _, m := range fallbackRefPattern.FindAllStringSubmatch(body, -1) {
+		if m[1] == key {
+			return atoi(m[2]), true
+		}
+	}
+	return 0, false
+}
+
+// analyzeFallback recovers basic information by scanning the raw bytes when pdfcpu cannot read
+// the file: the trailer, the Info dictionary and the page tree are located with regular
+// expressions, including inside FlateDecode object streams. Results are less reliable.
+func (pa *PDFAnalyzer) analyzeFallback(data []byte, info *PDFInfo) error {
+	// Sem cabeçalho %PDF não há o que recuperar
+	header := data
+	if len(header) > 1024 {
+		header = header[:1024]
+	}
+	if !bytes.Contains(header, []byte("%PDF-")) {
+		return fmt.Errorf("no PDF header found")
+	}
+
+	objects := fallbackObjects(data)
+
+	if info.PDFVersion == "" {
+		if m := fallbackVersionPattern.FindSubmatch(data); m != nil {
+			info.PDFVersion = string(m[1])
+			info.HeaderVersion = info.PDFVersion
+		}
+	}
+
+	// Trailer clássico ou dicionário de um fluxo XRef
+	trailer := ""
+	if all := fallbackTrailerPattern.FindAllSubmatch(data, -1); len(all) > 0 {
+		trailer = string(all[len(all)-1][1])
+	} else {
+		for _, body := range objects {
+			if strings.Contains(body, "/XRef") && strings.Contains(body, "/Root") {
+				trailer = body
+			}
+		}
+	}
+
+	if infoNr, found := fallbackRef(trailer, "Info"); found {
+		if infoBody, ok := objects[infoNr]; ok {
+			info.Title = fallbackStringEntry(objects, infoBody, "Title")
+			info.Author = fallbackStringEntry(objects, infoBody, "Author")
+			info.Subject = fallbackStringEntry(objects, infoBody, "Subject")
+			info.Creator = fallbackStringEntry(objects, infoBody, "Creator")
+			info.Producer = fallbackStringEntry(objects, infoBody, "Producer")
+			info.CreationDate = fallbackStringEntry(objects, infoBody, "CreationDate")
+			info.ModDate = fallbackStringEntry(objects, infoBody, "ModDate")
+		}
+	}
+	parseDocumentDates(info)
+	setSoftwareFamilies(info)
+	info.IsEncrypted = strings.Contains(trailer, "/Encrypt")
+	fallbackFileIdentifier(trailer, info)
+	if info.IsEncrypted {
+		fallbackSecurityHandler(objects, trailer, info)
+	}
+
+	// Contagem de páginas pela árvore de páginas, ou contando objetos /Type /Page
+	if rootNr, found := fallbackRef(trailer, "Root"); found {
+		if rootBody, ok := objects[rootNr]; ok {
+			if pagesNr, found := fallbackRef(rootBody, "Pages"); found {
+				if pagesBody, ok := objects[pagesNr]; ok {
+					if cm := fallbackCountPattern.FindStringSubmatch(pagesBody); cm != nil {
+						info.PageCount = atoi(cm[1])
+					}
+				}
+			}
+		}
+	}
+	if info.PageCount == 0 {
+		for _, body := range objects {
+			if fallbackPageTypeRegexp.MatchString(body) {
+				info.PageCount++
+			}
+		}
+	}
+
+	pa.analyzeRevisionVersions(data, info)
+	if n := len(info.RevisionVersions); n > 0 && info.HeaderVersion != "" && compareVersions(info.RevisionVersions[n-1], info.HeaderVersion) > 0 {
+		info.PDFVersion = info.RevisionVersions[n-1]
+		info.VersionOverridden = true
+	}
+
+	info.FallbackParsing = true
+	return nil
+}
+
+// fallbackObjects maps object numbers to their source text, including objects stored in
+// FlateDecode object streams. Later definitions win, as with incremental updates.
+func fallbackObjects(data []byte) map[int]string {
+	objects := make(map[int]string)
+	for _, m := range fallbackObjPattern.FindAllSubmatch(data, -1) {
+		objNr := atoi(string(m[1]))
+		body := m[3]
+		objects[objNr] = string(body)
+
+		sm := fallbackStreamPattern.FindSubmatch(body)
+		if sm == nil {
+			continue
+		}
+		dict := string(sm[1])
+		objects[objNr] = dict
+		if !strings.Contains(dict, "/ObjStm") || !strings.Contains(dict, "/FlateDecode") {
+			continue
+		}
+		content, err := inflate(sm[2])
+		if err != nil {
+			continue
+		}
+		first := fallbackFirstPattern.FindStringSubmatch(dict)
+		if first == nil || atoi(first[1]) > len(content) {
+			continue
+		}
+		firstOffset := atoi(first[1])
+
+		// Cabeçalho: pares "número deslocamento"
+		header := strings.Fields(string(content[:firstOffset]))
+		for i := 0; i+1 < len(header); i += 2 {
+			start := firstOffset + atoi(header[i+1])
+			end := len(content)
+			if i+3 < len(header) {
+				end = firstOffset + atoi(header[i+3])
+			}
+			if start < 0 || start > end || end > len(content) {
+				continue
+			}
+			objects[atoi(header[i])] = string(content[start:end])
+		}
+	}
+	return objects
+}
+
+// inflate decompresses FlateDecode data, keeping whatever could be read from a truncated stream
+func inflate(data []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	out, err := io.ReadAll(r)
+	if len(out) > 0 {
+		return out, nil
+	}
+	return out, err
+}
+
+// fallbackStringEntry reads a string entry from dictionary source text, following an
+// indirect reference if needed
+func fallbackStringEntry(objects map[int]string, body, key string) string {
+	if objNr, found := fallbackRef(body, key); found {
+		body = "/" + key + " " + objects[objNr]
+	}
+
+	start := -1
+	for _, idx := range fallbackStringStartPattern.FindAllStringSubmatchIndex(body, -1) {
+		if body[idx[2]:idx[3]] == key {
+			start = idx[1] - 1
+			break
+		}
+	}
+	if start < 0 {
+		return ""
+	}
+	raw := []byte(body)
+
+	if raw[start] == '(' {
+		end := skipLiteralString(raw, start)
+		literal := body[start+1 : end]
+		if raw[end-1] == ')' {
+			literal = body[start+1 : end-1]
+		}
+		if decoded, err := types.StringLiteralToString(types.StringLiteral(literal)); err == nil {
+			return decoded
+		}
+		return literal
+	}
+
+	end := strings.IndexByte(body[start:], '>')
+	if end < 0 {
+		return ""
+	}
+	hex := body[start+1 : start+end]
+	if decoded, err := types.HexLiteralToString(types.HexLiteral(hex)); err == nil {
+		return decoded
+	}
+	return ""
+}
+
+// atoi converts a decimal string, returning 0 when it is not a number
+func atoi(s string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -1,19 +1,75 @@
 package main
 
 import (
+	"bytes"
 	"crypto/md5"
+	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/sha512"
 	"fmt"
+	"hash"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
-// getFileInfo extracts basic file information
-func (pa *PDFAnalyzer) getFileInfo(filePath string, info *PDFInfo) error {
+// hashAlgorithms are the digests --hash can compute, in report order
+var hashAlgorithms = []struct {
+	Name  string
+	Label string
+	New   func() hash.Hash
+}{
+	{"md5", "MD5", md5.New},
+	{"sha1", "SHA1", sha1.New},
+	{"sha256", "SHA256", sha256.New},
+	{"sha512", "SHA512", sha512.New},
+}
+
+// defaultHashAlgorithms are computed when --hash is not given
+var defaultHashAlgorithms = []string{"md5", "sha256"}
+
+// parseHashList parses the --hash value: a comma-separated list of algorithms, or "none".
+// The result is in report order, without duplicates.
+func parseHashList(value string) ([]string, error) {
+	requested := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch {
+		case name == "none":
+			return []string{}, nil
+		case name == "":
+			continue
+		case !isHashAlgorithm(name):
+			return nil, fmt.Errorf("unknown hash algorithm %q (expected md5, sha1, sha256, sha512 or none)", name)
+		}
+		requested[name] = true
+	}
+
+	algorithms := []string{}
+	for _, alg := range hashAlgorithms {
+		if requested[alg.Name] {
+			algorithms = append(algorithms, alg.Name)
+		}
+	}
+	return algorithms, nil
+}
+
+func isHashAlgorithm(name string) bool {
+	for _, alg := range hashAlgorithms {
+		if alg.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// getFileInfo extracts basic file information and returns the file contents. The file is read
+// once, feeding the requested hashes and the buffer shared by the other analyses at the same time.
+func (pa *PDFAnalyzer) getFileInfo(filePath string, info *PDFInfo) ([]byte, error) {
 	stat, err := os.Stat(filePath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	info.FileName = filepath.Base(filePath)
@@ -22,27 +78,40 @@ func (pa *PDFAnalyzer) getFileInfo(filePath string, info *PDFInfo) error {
 	info.FileSizeHuman = formatFileSize(stat.Size())
 	info.LastModified = stat.ModTime()
 
-	// Calcular hashes
+	// Ler o arquivo uma única vez, calculando os hashes durante a leitura
 	file, err := os.Open(filePath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer file.Close()
 
-	// MD5
-	md5Hash := md5.New()
-	if _, err := io.Copy(md5Hash, file); err != nil {
-		return err
+	var buf bytes.Buffer
+	buf.Grow(int(stat.Size()))
+
+	requested := pa.HashAlgorithms
+	if requested == nil {
+		requested = defaultHashAlgorithms
+	}
+	hashers := make(map[string]hash.Hash)
+	writers := []io.Writer{&buf}
+	for _, alg := range hashAlgorithms {
+		for _, name := range requested {
+			if name == alg.Name {
+				hashers[alg.Name] = alg.New()
+				writers = append(writers, hashers[alg.Name])
+			}
+		}
 	}
-	info.MD5Hash = fmt.Sprintf("%x", md5Hash.Sum(nil))
 
-	// SHA256
-	file.Seek(0, 0)
-	sha256Hash := sha256.New()
-	if _, err := io.Copy(sha256Hash, file); err != nil {
-		return err
+	if _, err := io.Copy(io.MultiWriter(writers...), file); err != nil {
+		return nil, err
+	}
+	if len(hashers) > 0 {
+		info.Hashes = make(map[string]string, len(hashers))
+		for name, h := range hashers {
+			info.Hashes[name] = fmt.Sprintf("%x", h.Sum(nil))
+		}
 	}
-	info.SHA256Hash = fmt.Sprintf("%x", sha256Hash.Sum(nil))
 
-	return nil
+	return buf.Bytes(), nil
 }
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// standardStreamFilters are the filter names the PDF specification allows on streams.
+// The abbreviations (AHx, Fl, ...) are only valid for inline images.
+var standardStreamFilters = map[string]bool{
+	"ASCIIHexDecode":  true,
+	"ASCII85Decode":   true,
+	"LZWDecode":       true,
+	"FlateDecode":     true,
+	"RunLengthDecode": true,
+	"CCITTFaxDecode":  true,
+	"JBIG2Decode":     true,
+	"DCTDecode":       true,
+	"JPXDecode":       true,
+	"Crypt":           true,
+}
+
+// maxUsualFilterChain is the longest filter chain seen in ordinary files
+// (e.g. ASCII85Decode + FlateDecode)
+const maxUsualFilterChain = 2
+
+// streamFilterChain returns the filters of a stream dictionary, in decoding order
+func (pa *PDFAnalyzer) streamFilterChain(ctx *model.Context, d types.Dict) []string {
+	obj, found := d.Find("Filter")
+	if !found || obj == nil {
+		return nil
+	}
+	resolved, err := ctx.Dereference(obj)
+	if err != nil || resolved == nil {
+		return nil
+	}
+	switch v := resolved.(type) {
+	case types.Name:
+		return []string{string(v)}
+	case types.Array:
+		chain := make([]string, 0, len(v))
+		for _, f := range v {
+			if name, err := ctx.Dereference(f); err == nil {
+				if n, ok := name.(types.Name); ok {
+					chain = append(chain, string(n))
+				}
+			}
+		}
+		return chain
+	}
+	return nil
+}
+
+// unusualFilterChainReason explains why a filter chain looks like obfuscation, or returns ""
+func unusualFilterChainReason(chain []string) string {
+	if len(chain) > maxUsualFilterChain {
+		return fmt.Sprintf("%d filters deep", len(chain))
+	}
+	seen := make(map[string]bool)
+	for _, f := range chain {
+		if !standardStreamFilters[f] {
+			return fmt.Sprintf("non-standard filter %s", f)
+		}
+		if seen[f] {
+			return fmt.Sprintf("%s applied twice", f)
+		}
+		seen[f] = true
+	}
+	// Codificação ASCII depois de compressão de imagem não tem utilidade prática
+	for i, f := range chain {
+		if (f == "DCTDecode" || f == "JPXDecode" || f == "JBIG2Decode" || f == "CCITTFaxDecode") && i < len(chain)-1 {
+			return fmt.Sprintf("%s is not the last filter", f)
+		}
+	}
+	return ""
+}
+
+// analyzeFilterChains surveys the filter chains used by all streams and flags unusual ones
+func (pa *PDFAnalyzer) analyzeFilterChains(ctx *model.Context, info *PDFInfo) {
+	reasons := make(map[string]string)
+	pa.forEachObject(ctx, func(objNr int, obj types.Object) {
+		sd, ok := obj.(types.StreamDict)
+		if !ok {
+			return
+		}
+		chain := pa.streamFilterChain(ctx, sd.Dict)
+		if len(chain) == 0 {
+			return
+		}
+		key := strings.Join(chain, " > ")
+		if info.FilterChains == nil {
+			info.FilterChains = make(map[string]int)
+		}
+		info.FilterChains[key]++
+		if reason := unusualFilterChainReason(chain); reason != "" {
+			reasons[key] = reason
+		}
+	})
+
+	for key, reason := range reasons {
+		info.UnusualFilterChains = append(info.UnusualFilterChains,
+			fmt.Sprintf("%s (%s; %d streams)", key, reason, info.FilterChains[key]))
+	}
+	sort.Strings(info.UnusualFilterChains)
+}
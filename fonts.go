@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// maxResourceDepth limits how deep form XObjects nested in form XObjects are followed
+const maxResourceDepth = 8
+
+// pageResources returns the resources of a page, including inherited ones
+func (pa *PDFAnalyzer) pageResources(ctx *model.Context, pageNr int) (types.Dict, error) {
+	pageDict, _, inherited, err := ctx.PageDict(pageNr, true)
+	if err != nil || pageDict == nil {
+		return nil, fmt.Errorf("page %d not found", pageNr)
+	}
+	return pa.pageDictResources(ctx, pageDict, inherited), nil
+}
+
+// pageDictResources resolves the resources of a page dictionary, inherited from the page tree
+// when the page has none
+func (pa *PDFAnalyzer) pageDictResources(ctx *model.Context, pageDict types.Dict, inherited *model.InheritedPageAttrs) types.Dict {
+	resources, _ := ctx.DereferenceDict(pa.inheritedPageAttribute(ctx, pageDict, "Resources"))
+	if resources == nil && inherited != nil {
+		resources = inherited.Resources
+	}
+	return resources
+}
+
+// resourceCategory resolves one category of a resources dictionary, e.g. "Font"
+func (pa *PDFAnalyzer) resourceCategory(ctx *model.Context, resources types.Dict, category string) types.Dict {
+	if resources == nil {
+		return nil
+	}
+	obj, found := resources.Find(category)
+	if !found || obj == nil {
+		return nil
+	}
+	d, err := ctx.DereferenceDict(obj)
+	if err != nil {
+		return nil
+	}
+	return d
+}
+
+// fontLabel describes a font dictionary as "Helvetica (Type1)". The subset prefix of embedded
+// subsets ("ABCDEF+Helvetica") is dropped so that subsets of one font are listed once.
+func fontLabel(fontDict types.Dict) string {
+	name := "unnamed"
+	if baseFont := fontDict.NameEntry("BaseFont"); baseFont != nil && *baseFont != "" {
+		name = *baseFont
+		if i := strings.IndexByte(name, '+'); i == 6 && strings.ToUpper(name[:6]) == name[:6] {
+			name = name[7:]
+		}
+	}
+	if subtype := fontDict.NameEntry("Subtype"); subtype != nil {
+		return fmt.Sprintf("%s (%s)", name, *subtype)
+	}
+	return name
+}
+
+// fontIsEmbedded reports whether a font program comes with the file: its font descriptor (the
+// descendant font's, for Type0 fonts) has /FontFile, /FontFile2 or /FontFile3. Type3 fonts
+// draw their glyphs with content streams of their own, so they are always embedded.
+func (pa *PDFAnalyzer) fontIsEmbedded(ctx *model.Context, fontDict types.Dict) bool {
+	subtype := fontDict.NameEntry("Subtype")
+	if subtype != nil && *subtype == "Type3" {
+		return true
+	}
+	if subtype != nil && *subtype == "Type0" {
+		descendants, err := ctx.DereferenceArray(fontDict["DescendantFonts"])
+		if err != nil || len(descendants) == 0 {
+			return false
+		}
+		descendant, err := ctx.DereferenceDict(descendants[0])
+		if err != nil || descendant == nil {
+			return false
+		}
+		fontDict = descendant
+	}
+	descriptor, err := ctx.DereferenceDict(fontDict["FontDescriptor"])
+	if err != nil || descriptor == nil {
+		return false
+	}
+	for _, key := range []string{"FontFile", "FontFile2", "FontFile3"} {
+		if obj, found := descriptor.Find(key); found && obj != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// collectFonts adds the fonts of a resources dictionary to fonts, following form XObjects. The
+// value tells whether every font of that name seen so far is embedded. visited holds the object
+// numbers of the XObjects already walked.
+func (pa *PDFAnalyzer) collectFonts(ctx *model.Context, resources types.Dict, fonts map[string]bool, visited map[int]bool, depth int) {
+	for _, obj := range pa.resourceCategory(ctx, resources, "Font") {
+		fontDict, err := ctx.DereferenceDict(obj)
+		if err != nil || fontDict == nil {
+			continue
+		}
+		label := fontLabel(fontDict)
+		embedded, seen := fonts[label]
+		fonts[label] = pa.fontIsEmbedded(ctx, fontDict) && (embedded || !seen)
+	}
+
+	if depth >= maxResourceDepth {
+		return
+	}
+	for _, obj := range pa.resourceCategory(ctx, resources, "XObject") {
+		if ref, ok := obj.(types.IndirectRef); ok {
+			if visited[int(ref.ObjectNumber)] {
+				continue
+			}
+			visited[int(ref.ObjectNumber)] = true
+		}
+		sd, _, err := ctx.DereferenceStreamDict(obj)
+		if err != nil || sd == nil {
+			continue
+		}
+		if subtype := sd.Dict.NameEntry("Subtype"); subtype == nil || *subtype != "Form" {
+			continue
+		}
+		pa.collectFonts(ctx, pa.resourcesDict(ctx, sd.Dict), fonts, visited, depth+1)
+	}
+}
+
+// extractFonts lists the fonts used by the pages, deduplicated and sorted, noting which are
+// not embedded
+func (pa *PDFAnalyzer) extractFonts(ctx *model.Context, info *PDFInfo) {
+	fonts := make(map[string]bool)
+	visited := make(map[int]bool)
+	info.Completeness.Fonts = completenessFull
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		resources, err := pa.pageResources(ctx, i)
+		if err != nil {
+			info.Completeness.Fonts = completenessPartial
+			continue
+		}
+		pa.collectFonts(ctx, resources, fonts, visited, 0)
+	}
+
+	info.FontsUsed = make([]FontInfo, 0, len(fonts))
+	info.AllFontsEmbedded = true
+	for font, embedded := range fonts {
+		info.FontsUsed = append(info.FontsUsed, FontInfo{Name: font, Embedded: embedded})
+		info.AllFontsEmbedded = info.AllFontsEmbedded && embedded
+	}
+	sort.Slice(info.FontsUsed, func(i, j int) bool { return info.FontsUsed[i].Name < info.FontsUsed[j].Name })
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// acroFormDict resolves the document's AcroForm dictionary, if any
+func (pa *PDFAnalyzer) acroFormDict(ctx *model.Context) types.Dict {
+	if ctx == nil || ctx.RootDict == nil {
+		return nil
+	}
+	obj, found := ctx.RootDict.Find("AcroForm")
+	if !found || obj == nil {
+		return nil
+	}
+	acroForm, err := ctx.DereferenceDict(obj)
+	if err != nil {
+		return nil
+	}
+	return acroForm
+}
+
+// xfaOnlyAcroForm returns the catalog's /AcroForm entry, before validation, when the form has
+// an /XFA entry but no /Fields; pdfcpu's validation deletes such an entry as an empty form
+func (pa *PDFAnalyzer) xfaOnlyAcroForm(ctx *model.Context) types.Object {
+	root, err := ctx.Catalog()
+	if err != nil || root == nil {
+		return nil
+	}
+	obj, found := root.Find("AcroForm")
+	if !found || obj == nil {
+		return nil
+	}
+	acroForm, err := ctx.DereferenceDict(obj)
+	if err != nil || acroForm == nil {
+		return nil
+	}
+	if xfa, found := acroForm.Find("XFA"); !found || xfa == nil {
+		return nil
+	}
+	if fieldsObj, found := acroForm.Find("Fields"); found && fieldsObj != nil {
+		if fields, err := ctx.DereferenceArray(fieldsObj); err != nil || len(fields) > 0 {
+			return nil
+		}
+	}
+	return obj
+}
+
+// extractFormInfo extracts the AcroForm default appearance (/DA) and default resource fonts (/DR)
+func (pa *PDFAnalyzer) extractFormInfo(ctx *model.Context, info *PDFInfo) {
+	acroForm := pa.acroFormDict(ctx)
+	if acroForm == nil {
+		return
+	}
+
+	info.FormDefaultAppearance = getStringFromDict(acroForm, "DA")
+
+	// Fontes declaradas em /DR /Font
+	fontNames := map[string]bool{}
+	if drObj, found := acroForm.Find("DR"); found && drObj != nil {
+		if dr, err := ctx.DereferenceDict(drObj); err == nil && dr != nil {
+			if fontObj, found := dr.Find("Font"); found && fontObj != nil {
+				if fonts, err := ctx.DereferenceDict(fontObj); err == nil && fonts != nil {
+					for name, ref := range fonts {
+						fontNames[name] = true
+						entry := name
+						if fontDict, err := ctx.DereferenceDict(ref); err == nil && fontDict != nil {
+							if baseFont := getStringFromDict(fontDict, "BaseFont"); baseFont != "" {
+								entry = fmt.Sprintf("%s (%s)", name, baseFont)
+							}
+						}
+						info.FormDefaultFonts = append(info.FormDefaultFonts, entry)
+					}
+				}
+			}
+		}
+	}
+	sort.Strings(info.FormDefaultFonts)
+
+	// The font selected by the "/Name size Tf" operator in /DA must exist in /DR
+	if daFont := defaultAppearanceFont(info.FormDefaultAppearance); daFont != "" && !fontNames[daFont] {
+		info.FormDefaultFontMissing = true
+	}
+
+	if xfaObj, found := acroForm.Find("XFA"); found && xfaObj != nil {
+		info.HasXFA = true
+		packets := pa.xfaPackets(ctx, xfaObj)
+		for _, packet := range packets {
+			info.XFAPacketLength += len(packet)
+		}
+		info.XFATemplateName = xfaTemplateName(packets)
+	}
+
+	// Campos preenchidos e vazios (botões de ação não têm valor)
+	for _, field := range pa.formFields(ctx) {
+		info.FormFields = append(info.FormFields, FormFieldInfo{
+			Name:     field.FullName,
+			Type:     field.Type,
+			Value:    shortenText(strings.Join(strings.Fields(field.Value), " "), maxFieldValueText),
+			Required: field.Flags&fieldFlagRequired != 0,
+			ReadOnly: field.Flags&fieldFlagReadOnly != 0,
+		})
+		if field.IsPushButton() {
+			continue
+		}
+		if field.IsFilled() {
+			info.FilledFieldCount++
+		} else {
+			info.EmptyFieldCount++
+		}
+	}
+}
+
+// xfaSubformName matches the name of a subform element in an XFA template
+var xfaSubformName = regexp.MustCompile(`<subform\b[^>]*?\bname\s*=\s*["']([^"']*)["']`)
+
+// xfaPackets returns the decoded XFA packets keyed by name. /XFA is either one stream holding
+// the whole XDP document, stored under "", or an array of name and stream pairs ("preamble",
+// "template", "datasets", ...).
+func (pa *PDFAnalyzer) xfaPackets(ctx *model.Context, obj types.Object) map[string][]byte {
+	packets := make(map[string][]byte)
+	streamBytes := func(obj types.Object) []byte {
+		sd, _, err := ctx.DereferenceStreamDict(obj)
+		if err != nil || sd == nil || sd.Decode() != nil {
+			return nil
+		}
+		return sd.Content
+	}
+	resolved, err := ctx.Dereference(obj)
+	if err != nil || resolved == nil {
+		return packets
+	}
+	arr, ok := resolved.(types.Array)
+	if !ok {
+		if content := streamBytes(obj); content != nil {
+			packets[""] = content
+		}
+		return packets
+	}
+	for i := 0; i+1 < len(arr); i += 2 {
+		name := getStringFromDict(types.Dict{"n": arr[i]}, "n")
+		if content := streamBytes(arr[i+1]); content != nil {
+			packets[name] = append(packets[name], content...)
+		}
+	}
+	return packets
+}
+
+// xfaTemplateName returns the name of the first subform of the XFA template, which names
+// the form; the whole XDP document is searched when there is no separate template packet
+func xfaTemplateName(packets map[string][]byte) string {
+	template, found := packets["template"]
+	if !found {
+		template = packets[""]
+	}
+	if m := xfaSubformName.FindSubmatch(template); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
+
+// fieldTypeNames describe the field types (/FT) in the report
+var fieldTypeNames = map[string]string{
+	"Tx":  "text",
+	"Btn": "button",
+	"Ch":  "choice",
+	"Sig": "signature",
+}
+
+// fieldTypeName returns the report name of a field type, or the type itself when unknown
+func fieldTypeName(ft string) string {
+	if name, ok := fieldTypeNames[ft]; ok {
+		return name
+	}
+	if ft == "" {
+		return "no type"
+	}
+	return ft
+}
+
+// defaultAppearanceFont returns the font resource name selected by a /DA string, e.g. "Helv" for "/Helv 0 Tf 0 g"
+func defaultAppearanceFont(da string) string {
+	tokens := strings.Fields(da)
+	for i, tok := range tokens {
+		if tok == "Tf" && i >= 2 && strings.HasPrefix(tokens[i-2], "/") {
+			return strings.TrimPrefix(tokens[i-2], "/")
+		}
+	}
+	return ""
+}
+
+// maxFieldValueText is how many characters of each field value are shown
+const maxFieldValueText = 80
+
+// Field flags (/Ff) shared by several field types
+const (
+	fieldFlagReadOnly   = 1 << 0
+	fieldFlagRequired   = 1 << 1
+	fieldFlagPushButton = 1 << 16
+)
+
+// formField is a terminal field of the AcroForm field tree, with inherited attributes resolved
+type formField struct {
+	FullName string       // fully qualified name, e.g. "applicant.address.city"
+	Type     string       // /FT: Tx, Btn, Ch or Sig
+	Flags    int          // /Ff
+	Value    string       // /V rendered as text
+	HasValue bool         // /V is present
+	RawValue types.Object // /V as stored; for signature fields, the signature dictionary
+}
+
+// IsPushButton reports whether the field is a push button, which never holds a value
+func (f formField) IsPushButton() bool {
+	return f.Type == "Btn" && f.Flags&fieldFlagPushButton != 0
+}
+
+// IsFilled reports whether the field holds a non-empty value; a check box or radio
+// button set to /Off counts as empty
+func (f formField) IsFilled() bool {
+	if !f.HasValue || strings.TrimSpace(f.Value) == "" {
+		return false
+	}
+	return !(f.Type == "Btn" && f.Value == "Off")
+}
+
+// formFields walks the AcroForm /Fields tree through /Kids and returns its terminal fields.
+// /FT, /Ff and /V are inheritable, so they are passed down from parent fields.
+func (pa *PDFAnalyzer) formFields(ctx *model.Context) []formField {
+	acroForm := pa.acroFormDict(ctx)
+	if acroForm == nil {
+		return nil
+	}
+	fieldsObj, found := acroForm.Find("Fields")
+	if !found || fieldsObj == nil {
+		return nil
+	}
+
+	var fields []formField
+	visited := make(map[int]bool)
+	var walk func(obj types.Object, parent formField, depth int)
+	walk = func(obj types.Object, parent formField, depth int) {
+		if depth > 64 {
+			return
+		}
+		if ref, ok := obj.(types.IndirectRef); ok {
+			if visited[int(ref.ObjectNumber)] {
+				return
+			}
+			visited[int(ref.ObjectNumber)] = true
+		}
+		d, err := ctx.DereferenceDict(obj)
+		if err != nil || d == nil {
+			return
+		}
+
+		field := parent
+		if name := getStringFromDict(d, "T"); name != "" {
+			if field.FullName != "" {
+				field.FullName += "." + name
+			} else {
+				field.FullName = name
+			}
+		}
+		if ft := d.NameEntry("FT"); ft != nil {
+			field.Type = *ft
+		}
+		if ff, found := d.Find("Ff"); found {
+			if n, err := ctx.DereferenceInteger(ff); err == nil && n != nil {
+				field.Flags = int(*n)
+			}
+		}
+		if v, found := d.Find("V"); found && v != nil {
+			field.HasValue = true
+			field.Value = pa.fieldValueText(ctx, v)
+			field.RawValue = v
+		}
+
+		// Filhos com /T são campos; sem /T são apenas widgets do campo atual
+		var childFields []types.Object
+		if kidsObj, found := d.Find("Kids"); found {
+			if kids, err := ctx.DereferenceArray(kidsObj); err == nil {
+				for _, kid := range kids {
+					if kidDict, err := ctx.DereferenceDict(kid); err == nil && kidDict != nil {
+						if _, isField := kidDict.Find("T"); isField {
+							childFields = append(childFields, kid)
+						}
+					}
+				}
+			}
+		}
+		if len(childFields) == 0 {
+			fields = append(fields, field)
+			return
+		}
+		for _, kid := range childFields {
+			walk(kid, field, depth+1)
+		}
+	}
+
+	if roots, err := ctx.DereferenceArray(fieldsObj); err == nil {
+		for _, root := range roots {
+			walk(root, formField{}, 0)
+		}
+	}
+	return fields
+}
+
+// fieldValueText renders a field value (/V) as text: strings are decoded, names kept as is,
+// arrays (multiple selections) joined with ", " and signature dictionaries shown as "(signed)"
+func (pa *PDFAnalyzer) fieldValueText(ctx *model.Context, v types.Object) string {
+	resolved, err := ctx.Dereference(v)
+	if err != nil || resolved == nil {
+		return ""
+	}
+	switch value := resolved.(type) {
+	case types.StringLiteral, types.HexLiteral, types.Name:
+		return getStringFromDict(types.Dict{"V": value}, "V")
+	case types.Array:
+		parts := make([]string, 0, len(value))
+		for _, item := range value {
+			if text := pa.fieldValueText(ctx, item); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		return strings.Join(parts, ", ")
+	case types.Dict:
+		return "(signed)"
+	case types.StreamDict:
+		// Texto rico longo pode vir como fluxo
+		if value.Decode() == nil {
+			return string(value.Content)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// collectImages adds the image XObjects of a resources dictionary to images, keyed by object
+// number, following form XObjects. Direct (unreferenced) image streams get negative keys.
+func (pa *PDFAnalyzer) collectImages(ctx *model.Context, resources types.Dict, images map[int]bool, visited map[int]bool, depth int) {
+	for _, obj := range pa.resourceCategory(ctx, resources, "XObject") {
+		key := -(len(images) + 1)
+		if ref, ok := obj.(types.IndirectRef); ok {
+			key = int(ref.ObjectNumber)
+			if visited[key] {
+				continue
+			}
+			visited[key] = true
+		}
+		sd, _, err := ctx.DereferenceStreamDict(obj)
+		if err != nil || sd == nil {
+			continue
+		}
+		subtype := sd.Dict.NameEntry("Subtype")
+		if subtype == nil {
+			continue
+		}
+		switch *subtype {
+		case "Image":
+			images[key] = true
+		case "Form":
+			if depth < maxResourceDepth {
+				pa.collectImages(ctx, pa.resourcesDict(ctx, sd.Dict), images, visited, depth+1)
+			}
+		}
+	}
+}
+
+// countInlineImages counts the inline images (BI ... ID ... EI) of a parsed content stream
+func countInlineImages(ops []contentOp) int {
+	count := 0
+	for _, op := range ops {
+		if op.Operator == "BI" {
+			count++
+		}
+	}
+	return count
+}
+
+// pageImageCount counts the image XObjects available to a page and the inline images in its
+// content stream; ops is the page's parsed content
+func (pa *PDFAnalyzer) pageImageCount(ctx *model.Context, resources types.Dict, ops []contentOp) int {
+	images := make(map[int]bool)
+	pa.collectImages(ctx, resources, images, make(map[int]bool), 0)
+	return len(images) + countInlineImages(ops)
+}
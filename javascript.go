@@ -0,0 +1,108 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// maxJavaScriptSnippet is how many characters of each script are shown
+const maxJavaScriptSnippet = 100
+
+// documentJavaScripts returns the named document scripts of the /Names /JavaScript tree
+func (pa *PDFAnalyzer) documentJavaScripts(ctx *model.Context) map[string][]string {
+	namesObj, found := ctx.RootDict.Find("Names")
+	if !found || namesObj == nil {
+		return nil
+	}
+	names, err := ctx.DereferenceDict(namesObj)
+	if err != nil || names == nil {
+		return nil
+	}
+	treeObj, found := names.Find("JavaScript")
+	if !found || treeObj == nil {
+		return nil
+	}
+
+	scripts := make(map[string][]string)
+	var walk func(obj types.Object, depth int)
+	walk = func(obj types.Object, depth int) {
+		node, err := ctx.DereferenceDict(obj)
+		if err != nil || node == nil || depth > 32 {
+			return
+		}
+		if arr, err := ctx.DereferenceArray(node["Names"]); err == nil {
+			for i := 0; i+1 < len(arr); i += 2 {
+				name := getStringFromDict(types.Dict{"n": arr[i]}, "n")
+				if action, err := ctx.DereferenceDict(arr[i+1]); err == nil && action != nil {
+					if script := pa.actionScript(ctx, action); script != "" {
+						scripts[name] = append(scripts[name], script)
+					}
+				}
+			}
+		}
+		if kids, err := ctx.DereferenceArray(node["Kids"]); err == nil {
+			for _, kid := range kids {
+				walk(kid, depth+1)
+			}
+		}
+	}
+	walk(treeObj, 0)
+	return scripts
+}
+
+// javaScriptSnippet shortens a script to one line for the report
+func javaScriptSnippet(script string) string {
+	return shortenText(strings.Join(strings.Fields(script), " "), maxJavaScriptSnippet)
+}
+
+// actionScript returns the code of a JavaScript action, or "" for other actions
+func (pa *PDFAnalyzer) actionScript(ctx *model.Context, action types.Dict) string {
+	if s := action.NameEntry("S"); s == nil || *s != "JavaScript" {
+		return ""
+	}
+	js, found := action.Find("JS")
+	if !found || js == nil {
+		return ""
+	}
+	return pa.stringOrStreamText(ctx, js)
+}
+
+// analyzeJavaScript finds the scripts of the document: named document scripts, and JavaScript
+// actions wherever they can be triggered (see forEachAction). Each one is recorded with its
+// location and the start of its code.
+func (pa *PDFAnalyzer) analyzeJavaScript(ctx *model.Context, info *PDFInfo) {
+	if ctx == nil || ctx.RootDict == nil {
+		return
+	}
+	seen := make(map[string]bool)
+	add := func(location, script string) {
+		snippet := location + ": " + javaScriptSnippet(script)
+		if !seen[snippet] {
+			seen[snippet] = true
+			info.JavaScriptSnippets = append(info.JavaScriptSnippets, snippet)
+		}
+	}
+
+	named := pa.documentJavaScripts(ctx)
+	names := make([]string, 0, len(named))
+	for name := range named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, script := range named[name] {
+			add("Document script "+name, script)
+		}
+	}
+
+	pa.forEachAction(ctx, func(location string, action types.Dict) {
+		if script := pa.actionScript(ctx, action); script != "" {
+			add(location, script)
+		}
+	})
+
+	info.HasJavaScript = info.HasJavaScript || len(info.JavaScriptSnippets) > 0
+}
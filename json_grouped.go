@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// infoSections maps the first PDFInfo field of each report section to the section's JSON key.
+// Fields belong to the section of the closest preceding entry, in declaration order, so new
+// fields land in the right group as long as they are declared next to their peers.
+var infoSections = map[string]string{
+	"FileName":              "file",
+	"Title":                 "metadata",
+	"FallbackParsing":       "technical",
+	"SecurityHandler":       "security",
+	"HasDigitalSignatures":  "signatures",
+	"Pages":                 "pages",
+	"FormDefaultAppearance": "forms",
+	"TotalTextLength":       "content",
+	"Bookmarks":             "extras",
+	"Completeness":          "completeness",
+}
+
+// PrintGroupedJSON writes the analysis result to w as JSON organized by report section
+// (file, metadata, technical, security, ...), keeping the fields in declaration order
+func (pa *PDFAnalyzer) PrintGroupedJSON(w io.Writer, info *PDFInfo) error {
+	v := reflect.ValueOf(info).Elem()
+	t := v.Type()
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	open := false
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Name
+		if section, starts := infoSections[name]; starts || i == 0 {
+			if open {
+				buf.WriteString("},")
+			}
+			key, _ := json.Marshal(section)
+			buf.Write(key)
+			buf.WriteString(":{")
+			open = true
+		} else {
+			buf.WriteByte(',')
+		}
+
+		key, _ := json.Marshal(name)
+		value, err := json.Marshal(v.Field(i).Interface())
+		if err != nil {
+			return fmt.Errorf("field %s: %v", name, err)
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	if open {
+		buf.WriteByte('}')
+	}
+	buf.WriteByte('}')
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w, out.String())
+	return err
+}
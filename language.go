@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Limits of the language guess
+const (
+	languageSampleSize = 200000 // bytes of extracted text examined
+	minLanguageHits    = 5      // stopwords needed before guessing at all
+)
+
+// languageNames are the languages the detector knows, by ISO 639-1 code
+var languageNames = map[string]string{
+	"pt": "Portuguese",
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"de": "German",
+	"it": "Italian",
+}
+
+// languageStopwords are frequent short words of each language. Words shared by several
+// languages count for all of them; the distinctive ones decide.
+var languageStopwords = map[string][]string{
+	"pt": {"de", "a", "o", "que", "e", "do", "da", "em", "um", "uma", "para", "com", "não", "os", "as", "no", "na",
+		"dos", "das", "ao", "pelo", "pela", "é", "são", "mais", "este", "esta", "também", "seu", "sua"},
+	"en": {"the", "and", "of", "to", "in", "is", "that", "it", "for", "with", "as", "was", "on", "are", "be",
+		"this", "by", "not", "or", "from", "have", "an", "which", "at"},
+	"es": {"de", "la", "que", "el", "en", "y", "los", "las", "del", "se", "por", "un", "una", "para", "con",
+		"no", "es", "al", "lo", "como", "más", "pero", "sus", "su", "está", "también"},
+	"fr": {"de", "la", "le", "les", "et", "des", "en", "un", "une", "du", "est", "que", "pour", "dans", "qui",
+		"pas", "au", "sur", "avec", "ce", "il", "sont", "aux", "ou"},
+	"de": {"der", "die", "und", "das", "in", "den", "von", "zu", "ist", "mit", "sich", "des", "nicht", "ein",
+		"eine", "auf", "für", "dem", "auch", "im", "es", "werden", "sind"},
+	"it": {"di", "e", "il", "la", "che", "per", "un", "una", "in", "del", "della", "non", "con", "sono", "gli",
+		"le", "è", "nel", "alla", "anche", "questo", "si", "da"},
+}
+
+// detectLanguage guesses the dominant language of text by counting stopwords. The confidence
+// compares the winner with the runner-up: 0.5 is a tie, 1 means no other language matched. It
+// returns "" when the text has too few stopwords to tell.
+func detectLanguage(text string) (code string, confidence float64) {
+	if len(text) > languageSampleSize {
+		text = text[:languageSampleSize]
+	}
+	stopwords := make(map[string][]string)
+	for lang, words := range languageStopwords {
+		for _, w := range words {
+			stopwords[w] = append(stopwords[w], lang)
+		}
+	}
+
+	hits := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, w := range words {
+		langs := stopwords[w]
+		for _, lang := range langs {
+			hits[lang]++
+		}
+	}
+
+	best, second := "", 0
+	for lang, n := range hits {
+		if best == "" || n > hits[best] || (n == hits[best] && lang < best) {
+			if best != "" {
+				second = max(second, hits[best])
+			}
+			best = lang
+		} else {
+			second = max(second, n)
+		}
+	}
+	if best == "" || hits[best] < minLanguageHits {
+		return "", 0
+	}
+	// Empate com o segundo colocado dá 0.5; só a língua vencedora, 1
+	return best, float64(hits[best]) / float64(hits[best]+second)
+}
+
+// languageLabel formats a language code for the report, e.g. "Portuguese (pt)"
+func languageLabel(code string) string {
+	if name, ok := languageNames[code]; ok {
+		return name + " (" + code + ")"
+	}
+	return code
+}
+
+// languagesDiffer reports whether a declared language tag (e.g. "pt-BR") names a different
+// language than the detected code. Missing values never differ.
+func languagesDiffer(declared, detected string) bool {
+	if declared == "" || detected == "" {
+		return false
+	}
+	primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(declared)), "-")
+	return primary != detected
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// extractLayers reads the optional content groups (layers) from /OCProperties and determines
+// which ones are hidden when the document is opened, according to the default /D configuration
+func (pa *PDFAnalyzer) extractLayers(ctx *model.Context, info *PDFInfo) {
+	ocObj, found := ctx.RootDict.Find("OCProperties")
+	if !found || ocObj == nil {
+		return
+	}
+	ocProps, err := ctx.DereferenceDict(ocObj)
+	if err != nil || ocProps == nil {
+		return
+	}
+
+	ocgsObj, _ := ocProps.Find("OCGs")
+	ocgs, err := ctx.DereferenceArray(ocgsObj)
+	if err != nil || len(ocgs) == 0 {
+		return
+	}
+
+	// Estado padrão: /BaseState (ON se ausente), com exceções nas listas /ON e /OFF
+	baseOff := false
+	on := make(map[int]bool)
+	off := make(map[int]bool)
+	if dObj, found := ocProps.Find("D"); found && dObj != nil {
+		if d, err := ctx.DereferenceDict(dObj); err == nil && d != nil {
+			if base := d.NameEntry("BaseState"); base != nil && *base == "OFF" {
+				baseOff = true
+			}
+			pa.collectRefNumbers(ctx, d, "ON", on)
+			pa.collectRefNumbers(ctx, d, "OFF", off)
+		}
+	}
+
+	for _, ocgObj := range ocgs {
+		ref, isRef := ocgObj.(types.IndirectRef)
+		ocg, err := ctx.DereferenceDict(ocgObj)
+		if err != nil || ocg == nil {
+			continue
+		}
+		name := getStringFromDict(ocg, "Name")
+		if name == "" {
+			name = "(unnamed)"
+		}
+		hidden := baseOff
+		if isRef {
+			objNr := int(ref.ObjectNumber)
+			if off[objNr] {
+				hidden = true
+			} else if on[objNr] {
+				hidden = false
+			}
+		}
+		info.Layers = append(info.Layers, LayerInfo{Name: name, Visible: !hidden})
+		if hidden {
+			info.HiddenLayers = append(info.HiddenLayers, name)
+		}
+	}
+	info.HasLayers = len(info.Layers) > 0
+}
+
+// collectRefNumbers adds the object numbers of the indirect references in an array entry to set
+func (pa *PDFAnalyzer) collectRefNumbers(ctx *model.Context, d types.Dict, key string, set map[int]bool) {
+	obj, found := d.Find(key)
+	if !found || obj == nil {
+		return
+	}
+	arr, err := ctx.DereferenceArray(obj)
+	if err != nil {
+		return
+	}
+	for _, o := range arr {
+		if ref, ok := o.(types.IndirectRef); ok {
+			set[int(ref.ObjectNumber)] = true
+		}
+	}
+}
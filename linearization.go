@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// linearizationWindow is how far into the file the linearization parameter dictionary may
+// start; it must be the first object in the file
+const linearizationWindow = 1024
+
+var (
+	firstObjectPattern      = regexp.MustCompile(`(?s)^%PDF-\d\.\d[^\n\r]*[\r\n]+(?:%[^\r\n]*[\r\n]+)*\s*\d+\s+\d+\s+obj\s*<<(.*?)>>`)
+	linearizedKeyPattern    = regexp.MustCompile(`/Linearized\s+[\d.]+`)
+	xrefAtOffsetPattern     = regexp.MustCompile(`^\s*(?:xref\b|\d+\s+\d+\s+obj\b)`)
+	linearizationIntPattern = regexp.MustCompile(`/(\w+)\s+(\d+)`)
+)
+
+// linearizationParam returns an integer entry of the linearization parameter dictionary
+func linearizationParam(dict string, key string) (int64, bool) {
+	for _, m := range linearizationIntPattern.FindAllStringSubmatch(dict, -1) {
+		if m[1] == key {
+			n, err := strconv.ParseInt(m[2], 10, 64)
+			return n, err == nil
+		}
+	}
+	return 0, false
+}
+
+// analyzeLinearization validates the linearization parameter dictionary: it must be the first
+// object of the file, /L must equal the file size (an incremental update breaks it) and /T
+// must point at the main cross-reference table. /N must match the page count when known.
+func (pa *PDFAnalyzer) analyzeLinearization(data []byte, info *PDFInfo) {
+	head := data[:min(len(data), linearizationWindow)]
+	m := firstObjectPattern.FindSubmatch(head)
+	info.LinearizationValid = false
+	if m == nil || !linearizedKeyPattern.Match(m[1]) {
+		// pdfcpu pode ter visto a linearização mais adiante no arquivo, fora do lugar
+		if info.IsLinearized {
+			info.LinearizationIssues = append(info.LinearizationIssues, "the linearization dictionary is not the first object")
+		}
+		return
+	}
+	info.IsLinearized = true
+	dict := string(m[1])
+
+	if l, ok := linearizationParam(dict, "L"); !ok {
+		info.LinearizationIssues = append(info.LinearizationIssues, "missing /L (file length)")
+	} else if l != int64(len(data)) {
+		info.LinearizationIssues = append(info.LinearizationIssues,
+			fmt.Sprintf("/L is %d but the file has %d bytes (modified after linearization)", l, len(data)))
+	}
+
+	if t, ok := linearizationParam(dict, "T"); !ok {
+		info.LinearizationIssues = append(info.LinearizationIssues, "missing /T (main xref offset)")
+	} else if t >= int64(len(data)) || !xrefAtOffsetPattern.Match(data[t:]) {
+		info.LinearizationIssues = append(info.LinearizationIssues, fmt.Sprintf("/T offset %d does not point at a cross-reference section", t))
+	}
+
+	if n, ok := linearizationParam(dict, "N"); ok && info.PageCount > 0 && n != int64(info.PageCount) {
+		info.LinearizationIssues = append(info.LinearizationIssues, fmt.Sprintf("/N is %d but the document has %d pages", n, info.PageCount))
+	}
+	info.LinearizationValid = len(info.LinearizationIssues) == 0
+}
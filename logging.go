@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// verbosity is the level of diagnostic output, raised by --verbose; at 0 only the report,
+// warnings and errors are printed
+var verbosity int
+
+// debugf prints a "Debug:" line to stderr when --verbose is on
+func debugf(format string, args ...any) {
+	if verbosity < 1 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Debug: "+format+"\n", args...)
+}
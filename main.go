@@ -1,24 +1,304 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strings"
+	"time"
 )
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: go run . <pdf_path>")
-		os.Exit(1)
+	var assertions, failOn stringList
+	password := flag.String("password", "", "password to open an encrypted PDF (user or owner `password`)")
+	trustedTime := flag.String("trusted-time", "", "RFC3339 instant to check each signature's time against (e.g. 2024-06-30T23:59:59Z)")
+	metadataOnly := flag.Bool("metadata-only", false, "only print the normalized document metadata (Info, XMP and custom entries)")
+	listURLs := flag.Bool("list-urls", false, "only list the URLs found in link annotations, URI actions, JavaScript and XMP")
+	jsonOutput := flag.Bool("json", false, "print the result as JSON")
+	jsonGrouped := flag.Bool("json-grouped", false, "print the result as JSON grouped by report section")
+	csvOutput := flag.Bool("csv", false, "print the result as a CSV header and one row per file")
+	xmlOutput := flag.Bool("xml", false, "print the result as an XML document")
+	summaryOutput := flag.Bool("summary", false, "print one aligned line per file: pages, version, encryption, signatures and size")
+	flag.Var(&assertions, "assert", "exit with status 1 unless the expression holds, e.g. 'is_encrypted==false' (repeatable)")
+	flag.Var(&failOn, "fail-on", "exit with status 1 if the expression holds, e.g. 'has_java_script==true' (repeatable)")
+	recurseEmbedded := flag.Bool("recurse-embedded", false, "also analyze PDFs embedded as attachments, nesting their results under the parent")
+	crossCheckText := flag.Bool("cross-check-text", false, "also count text in the content streams and warn when it disagrees with the extracted text")
+	extractAttachments := flag.String("extract-attachments", "", "save the embedded files into this `directory` (existing files are not overwritten)")
+	computeEntropy := flag.Bool("entropy", false, "compute the entropy of each stream and flag near-random content (hidden payloads)")
+	sqlitePath := flag.String("sqlite", "", "append a row with the results to the pdf_files table of this SQLite `database` (requires the sqlite3 tool)")
+	caBundle := flag.String("ca-bundle", "", "PEM `file` of trusted CA certificates for signature chain validation (default: system roots)")
+	hashList := flag.String("hash", "md5,sha256", "file digests to compute: comma-separated `list` of md5, sha1, sha256, sha512, or none")
+	var pages pageRange
+	flag.Var(&pages, "pages", "list these pages in the report: all, N, N-M or N- (default: the first 5)")
+	allPages := flag.Bool("all-pages", false, "list every page in the report (same as --pages all)")
+	recursive := flag.Bool("recursive", false, "when a directory is given, also analyze the PDFs in its subdirectories")
+	verifyOnly := flag.Bool("verify-only", false, "only print the digital signatures section; exit with status 2 unless every signature is valid and trusted")
+	dumpText := flag.Bool("dump-text", false, "print the extracted plain text of each page instead of the report")
+	textOut := flag.String("text-out", "", "also write the extracted plain text of each page to this `file`")
+	quiet := flag.Bool("quiet", false, "print the text report as plain 'key: value' lines, without banners, emoji or footer")
+	var verbose bool
+	flag.BoolVar(&verbose, "verbose", false, "print debug information (analysis steps and their timing) to stderr")
+	flag.BoolVar(&verbose, "v", false, "shorthand for --verbose")
+	silent := flag.Bool("silent", false, "print nothing to stdout; report the outcome only through the exit status")
+	var outputPath string
+	flag.StringVar(&outputPath, "output", "", "write the report, JSON or CSV to this `file` instead of stdout")
+	flag.StringVar(&outputPath, "o", "", "shorthand for --output")
+	flag.Usage = printUsage
+	args := parseCommandLine(flag.CommandLine, os.Args[1:])
+
+	if len(args) < 1 {
+		flag.Usage()
+		os.Exit(exitFailure)
+	}
+
+	if verbose {
+		verbosity = 1
+	}
+
+	// Descartar toda a saída padrão; os avisos da análise vão para stderr
+	if *silent {
+		devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+		if err != nil {
+			log.Fatalf("Error opening %s: %v", os.DevNull, err)
+		}
+		defer devNull.Close()
+		os.Stdout = devNull
 	}
 
-	pdfPath := os.Args[1]
-	
-	analyzer := &PDFAnalyzer{}
-	info, err := analyzer.AnalyzePDF(pdfPath)
+	// A saída vai para stdout ou, com --output, para o arquivo
+	var out io.Writer = os.Stdout
+	var outFile *os.File
+	if outputPath != "" {
+		f, err := os.Create(outputPath)
+		if err != nil {
+			log.Fatalf("Error creating --output file: %v", err)
+		}
+		outFile = f
+		out = f
+	}
+
+	analyzer := &PDFAnalyzer{CrossCheckText: *crossCheckText, RecurseEmbedded: *recurseEmbedded, ComputeEntropy: *computeEntropy}
+	analyzer.Password = *password
+	analyzer.ExtractAttachmentsDir = *extractAttachments
+	analyzer.PageRange = pages
+	analyzer.KeepText = *dumpText || *textOut != ""
+	analyzer.Quiet = *quiet
+	hashes, err := parseHashList(*hashList)
 	if err != nil {
-		log.Fatalf("Error analyzing PDF: %v", err)
+		log.Fatalf("Invalid --hash value: %v", err)
+	}
+	analyzer.HashAlgorithms = hashes
+	if *caBundle != "" {
+		roots, err := loadCABundle(*caBundle)
+		if err != nil {
+			log.Fatalf("Error loading --ca-bundle: %v", err)
+		}
+		analyzer.TrustRoots = roots
+	}
+	if *allPages {
+		analyzer.PageRange = pageRange{First: 1}
+	}
+	if *trustedTime != "" {
+		t, err := time.Parse(time.RFC3339, *trustedTime)
+		if err != nil {
+			log.Fatalf("Invalid --trusted-time value %q: expected RFC3339, e.g. 2024-06-30T23:59:59Z", *trustedTime)
+		}
+		analyzer.TrustedTime = t
 	}
 
-	analyzer.PrintReport(info)
+	paths := collectPDFPaths(args, *recursive)
+	if len(paths) == 0 {
+		log.Fatalf("No PDF files found in %s", strings.Join(args, ", "))
+	}
+	// Um único arquivo passado diretamente mantém a saída de sempre, sem resumo
+	batch := len(paths) > 1 || paths[0] != args[0]
+
+	opts := runOptions{
+		ListURLs:     *listURLs,
+		MetadataOnly: *metadataOnly,
+		JSON:         *jsonOutput,
+		JSONGrouped:  *jsonGrouped,
+		CSV:          *csvOutput,
+		XML:          *xmlOutput,
+		Summary:      *summaryOutput,
+		SQLitePath:   *sqlitePath,
+		VerifyOnly:   *verifyOnly,
+		DumpText:     *dumpText,
+		Batch:        batch,
+		Out:          out,
+	}
+	if opts.Summary {
+		opts.NameWidth = summaryNameWidth(paths)
+	}
+	if *textOut != "" {
+		f, err := os.Create(*textOut)
+		if err != nil {
+			log.Fatalf("Error creating --text-out file: %v", err)
+		}
+		defer f.Close()
+		opts.TextOut = f
+	}
+	if opts.CSV && !opts.ListURLs && !opts.MetadataOnly {
+		if err := analyzer.PrintCSVHeader(out); err != nil {
+			log.Fatalf("Error writing CSV: %v", err)
+		}
+	}
+
+	var summary batchSummary
+	exitCode := exitClean
+	for i, path := range paths {
+		if batch && i > 0 && !opts.machineReadable() && !*quiet {
+			fmt.Fprintln(out)
+		}
+
+		info, err := runFile(analyzer, path, opts)
+		summary.add(path, info, err)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error analyzing %s: %v\n", path, err)
+			exitCode = worseExitCode(exitCode, exitFailure)
+			continue
+		}
+		if info != nil {
+			if opts.VerifyOnly {
+				exitCode = worseExitCode(exitCode, verifyExitCode(info))
+			} else {
+				exitCode = worseExitCode(exitCode, outcomeExitCode(info))
+			}
+		}
+
+		if info != nil && (len(assertions) > 0 || len(failOn) > 0) {
+			ok, failures, err := checkAssertions(info, assertions, failOn)
+			if err != nil {
+				log.Fatalf("Invalid assertion: %v", err)
+			}
+			if !ok {
+				exitCode = worseExitCode(exitCode, exitFailure)
+				if !*silent {
+					for _, failure := range failures {
+						if batch {
+							failure = path + ": " + failure
+						}
+						fmt.Fprintln(os.Stderr, failure)
+					}
+				}
+			}
+		}
+	}
+
+	// O resumo não pode se misturar à saída JSON/CSV/XML/--summary
+	if batch && !*silent {
+		if opts.machineReadable() {
+			summary.print(os.Stderr)
+		} else {
+			writeQuietly(out, *quiet, summary.print)
+		}
+	}
+
+	// Erros de escrita adiados só aparecem no Close
+	if outFile != nil {
+		if err := outFile.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", outputPath, err)
+			exitCode = worseExitCode(exitCode, exitFailure)
+		}
+	}
+
+	if exitCode != exitClean {
+		os.Exit(exitCode)
+	}
+}
+
+// runOptions selects what is done with each analyzed file
+type runOptions struct {
+	ListURLs     bool
+	MetadataOnly bool
+	JSON         bool
+	JSONGrouped  bool
+	CSV          bool
+	XML          bool
+	Summary      bool // one line per file
+	NameWidth    int  // width of the file name column of --summary
+	SQLitePath   string
+	VerifyOnly   bool
+	DumpText     bool      // print the page text instead of the report
+	TextOut      io.Writer // also write the page text here; nil for none
+	Batch        bool      // several files are analyzed: label each file's text
+	Out          io.Writer // where the report, JSON or CSV goes: stdout or the --output file
+}
+
+// machineReadable reports whether the output is JSON, CSV, XML or --summary lines rather than
+// the text report
+func (o runOptions) machineReadable() bool {
+	return o.JSON || o.JSONGrouped || o.CSV || o.XML || o.Summary
+}
+
+// runFile analyzes one file and prints its output. The returned info is nil with --list-urls.
+func runFile(analyzer *PDFAnalyzer, path string, opts runOptions) (info *PDFInfo, err error) {
+	// Um arquivo danificado que derrube a análise não pode interromper o lote
+	defer func() {
+		if r := recover(); r != nil {
+			info, err = nil, fmt.Errorf("analysis crashed: %v", r)
+		}
+	}()
+
+	if opts.ListURLs {
+		urls, err := analyzer.ExtractURLs(path)
+		if err != nil {
+			return nil, fmt.Errorf("extracting URLs: %v", err)
+		}
+		if err := printURLList(opts.Out, urls, opts.JSON); err != nil {
+			return nil, fmt.Errorf("printing URLs: %v", err)
+		}
+		return nil, nil
+	}
+
+	if opts.MetadataOnly {
+		info, err = analyzer.AnalyzeMetadata(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading metadata: %v", err)
+		}
+		if err := printMetadataMap(opts.Out, normalizedMetadata(info), opts.JSON); err != nil {
+			return nil, fmt.Errorf("printing metadata: %v", err)
+		}
+	} else {
+		info, err = analyzer.AnalyzePDF(path)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case opts.Summary:
+			err = writeSummaryLine(opts.Out, path, info, opts.NameWidth)
+		case opts.CSV:
+			err = analyzer.PrintCSV(opts.Out, info)
+		case opts.JSONGrouped:
+			err = analyzer.PrintGroupedJSON(opts.Out, info)
+		case opts.XML:
+			err = analyzer.PrintXML(opts.Out, info)
+		case opts.JSON:
+			err = analyzer.PrintJSON(opts.Out, info)
+		case opts.VerifyOnly:
+			err = analyzer.PrintSignatureReport(opts.Out, info)
+		case opts.DumpText:
+			err = analyzer.writeFileText(opts.Out, path, info, opts.Batch)
+		default:
+			err = analyzer.PrintReport(opts.Out, info)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("writing output: %v", err)
+		}
+		if opts.TextOut != nil {
+			if err := analyzer.writeFileText(opts.TextOut, path, info, opts.Batch); err != nil {
+				return nil, fmt.Errorf("writing text: %v", err)
+			}
+		}
+	}
+
+	if opts.SQLitePath != "" {
+		if err := writeSQLite(opts.SQLitePath, info); err != nil {
+			return nil, fmt.Errorf("writing to SQLite database: %v", err)
+		}
+	}
+	return info, nil
 }
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// xmpStandardKeys maps XMP properties to the normalized key of their Info dictionary equivalent
+var xmpStandardKeys = map[string]string{
+	"dc:title":        "title",
+	"dc:creator":      "author",
+	"dc:description":  "subject",
+	"pdf:Keywords":    "keywords",
+	"xmp:CreatorTool": "creator",
+	"pdf:Producer":    "producer",
+	"xmp:CreateDate":  "creationdate",
+	"xmp:ModifyDate":  "moddate",
+	"pdf:Trapped":     "trapped",
+}
+
+// normalizedMetadata unifies the Info, custom and XMP metadata into one map with lowercase keys.
+// Info values take the standard keys; an XMP property that agrees with its Info equivalent is
+// dropped, while one that disagrees is kept under its own lowercased XMP name (e.g. "dc:title").
+func normalizedMetadata(info *PDFInfo) map[string]string {
+	result := make(map[string]string)
+
+	infoValues := map[string]string{
+		"title":        info.Title,
+		"author":       info.Author,
+		"subject":      info.Subject,
+		"keywords":     info.Keywords,
+		"creator":      info.Creator,
+		"producer":     info.Producer,
+		"creationdate": info.CreationDate,
+		"moddate":      info.ModDate,
+		"trapped":      info.Trapped,
+	}
+	for key, value := range infoValues {
+		if value != "" {
+			result[key] = value
+		}
+	}
+
+	for key, value := range info.CustomMetadata {
+		normalized := strings.ToLower(key)
+		if _, exists := result[normalized]; !exists {
+			result[normalized] = value
+		}
+	}
+
+	for prop, value := range info.XMPProperties {
+		if standardKey, ok := xmpStandardKeys[prop]; ok {
+			existing, found := result[standardKey]
+			if !found {
+				result[standardKey] = value
+				continue
+			}
+			if metadataValuesAgree(standardKey, existing, value) {
+				continue
+			}
+		}
+		result[strings.ToLower(prop)] = value
+	}
+
+	return result
+}
+
+// metadataValuesAgree compares an Info value with its XMP counterpart. Dates are compared on
+// their date and time digits only, since Info ("D:20230115103000+02'00'") and XMP
+// ("2023-01-15T10:30:00+02:00") write the same instant differently.
+func metadataValuesAgree(key, infoValue, xmpValue string) bool {
+	if key == "creationdate" || key == "moddate" {
+		a, b := dateDigits(infoValue), dateDigits(xmpValue)
+		n := len(a)
+		if len(b) < n {
+			n = len(b)
+		}
+		return n >= 8 && a[:n] == b[:n]
+	}
+	return strings.EqualFold(strings.TrimSpace(infoValue), strings.TrimSpace(xmpValue))
+}
+
+// dateDigits returns up to the first 14 digits (YYYYMMDDHHmmSS) of a date string
+func dateDigits(s string) string {
+	var digits strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+			if digits.Len() == 14 {
+				break
+			}
+		}
+	}
+	return digits.String()
+}
+
+// printMetadataMap writes normalized metadata to w as sorted key=value lines or as a JSON object
+func printMetadataMap(w io.Writer, metadata map[string]string, asJSON bool) error {
+	if asJSON {
+		data, err := json.MarshalIndent(metadata, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Manter um par por linha
+	escaper := strings.NewReplacer("\r", `\r`, "\n", `\n`)
+	ew := &errWriter{w: w}
+	for _, key := range keys {
+		fmt.Fprintf(ew, "%s=%s\n", key, escaper.Replace(metadata[key]))
+	}
+	return ew.err
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// forEachObject calls fn for every object in the cross-reference table, resolved, in object number order
+func (pa *PDFAnalyzer) forEachObject(ctx *model.Context, fn func(objNr int, obj types.Object)) {
+	if ctx.XRefTable == nil || ctx.XRefTable.Table == nil {
+		return
+	}
+
+	objNrs := make([]int, 0, len(ctx.XRefTable.Table))
+	for objNr := range ctx.XRefTable.Table {
+		objNrs = append(objNrs, objNr)
+	}
+	sort.Ints(objNrs)
+
+	for _, objNr := range objNrs {
+		entry := ctx.XRefTable.Table[objNr]
+		if entry == nil || entry.Free {
+			continue
+		}
+		genNr := 0
+		if entry.Generation != nil {
+			genNr = *entry.Generation
+		}
+		obj, err := ctx.Dereference(types.IndirectRef{
+			ObjectNumber:     types.Integer(objNr),
+			GenerationNumber: types.Integer(genNr),
+		})
+		if err != nil || obj == nil {
+			continue
+		}
+		fn(objNr, obj)
+	}
+}
+
+// forEachDict calls fn for every dictionary in the document, including dictionaries nested
+// inside other objects and the dictionaries of streams. Objects are visited in object number order.
+func (pa *PDFAnalyzer) forEachDict(ctx *model.Context, fn func(objNr int, d types.Dict)) {
+	var walk func(obj types.Object, objNr int, depth int)
+	walk = func(obj types.Object, objNr int, depth int) {
+		if obj == nil || depth > 64 {
+			return
+		}
+		switch v := obj.(type) {
+		case types.Dict:
+			fn(objNr, v)
+			for _, value := range v {
+				walk(value, objNr, depth+1)
+			}
+		case types.StreamDict:
+			walk(v.Dict, objNr, depth+1)
+		case *types.StreamDict:
+			walk(v.Dict, objNr, depth+1)
+		case types.Array:
+			for _, value := range v {
+				walk(value, objNr, depth+1)
+			}
+		}
+	}
+
+	pa.forEachObject(ctx, func(objNr int, obj types.Object) {
+		walk(obj, objNr, 0)
+	})
+}
+
+// stringOrStreamText returns the text of an object that may be a string or a stream,
+// as used for JavaScript (/JS) and similar entries
+func (pa *PDFAnalyzer) stringOrStreamText(ctx *model.Context, obj types.Object) string {
+	resolved, err := ctx.Dereference(obj)
+	if err != nil || resolved == nil {
+		return ""
+	}
+	switch v := resolved.(type) {
+	case types.StringLiteral, types.HexLiteral:
+		return getStringFromDict(types.Dict{"v": v}, "v")
+	case types.StreamDict:
+		if err := v.Decode(); err != nil {
+			return ""
+		}
+		return string(v.Content)
+	}
+	return ""
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Thresholds of the optimization heuristics
+const (
+	minCompressibleStream = 1024      // uncompressed streams smaller than this are not worth reporting
+	largeMetadataSize     = 64 * 1024 // XMP metadata above this size is reported
+)
+
+// analyzeOptimization estimates how much an optimizer could save: duplicate images that could
+// be shared, uncompressed streams, oversized metadata and objects nothing refers to.
+// It only reports; the file is not rewritten.
+func (pa *PDFAnalyzer) analyzeOptimization(ctx *model.Context, info *PDFInfo) {
+	var savings int64
+	imageGroups := make(map[[32]byte][]int64)
+	var uncompressedCount int
+	var uncompressedSavings int64
+	var metadataSize, metadataPadding int64
+
+	pa.forEachObject(ctx, func(objNr int, obj types.Object) {
+		sd, ok := obj.(types.StreamDict)
+		if !ok {
+			return
+		}
+		subtype := sd.Dict.NameEntry("Subtype")
+		typ := sd.Dict.NameEntry("Type")
+
+		// Imagens idênticas que poderiam ser um único objeto
+		if subtype != nil && *subtype == "Image" && len(sd.Raw) > 0 {
+			hash := sha256.Sum256(sd.Raw)
+			imageGroups[hash] = append(imageGroups[hash], int64(len(sd.Raw)))
+		}
+
+		if typ != nil && *typ == "Metadata" {
+			if sd.Decode() == nil {
+				metadataSize += int64(len(sd.Content))
+				metadataPadding += int64(xmpPaddingLength(sd.Content))
+			}
+			return
+		}
+
+		// Fluxos sem compressão
+		if len(pa.streamFilterChain(ctx, sd.Dict)) == 0 && sd.Decode() == nil && len(sd.Content) >= minCompressibleStream {
+			if saved := int64(len(sd.Content) - flateSize(sd.Content)); saved > 0 {
+				uncompressedCount++
+				uncompressedSavings += saved
+			}
+		}
+	})
+
+	var duplicateImages int
+	var duplicateSavings int64
+	for _, sizes := range imageGroups {
+		for _, size := range sizes[1:] {
+			duplicateImages++
+			duplicateSavings += size
+		}
+	}
+	if duplicateImages > 0 {
+		savings += duplicateSavings
+		info.OptimizationFindings = append(info.OptimizationFindings,
+			fmt.Sprintf("%d duplicate images could be shared (~%s)", duplicateImages, formatFileSize(duplicateSavings)))
+	}
+	if uncompressedCount > 0 {
+		savings += uncompressedSavings
+		info.OptimizationFindings = append(info.OptimizationFindings,
+			fmt.Sprintf("%d uncompressed streams could be Flate-compressed (~%s)", uncompressedCount, formatFileSize(uncompressedSavings)))
+	}
+	if metadataSize > largeMetadataSize {
+		savings += metadataPadding
+		info.OptimizationFindings = append(info.OptimizationFindings,
+			fmt.Sprintf("XMP metadata is large (%s, of which %s padding)", formatFileSize(metadataSize), formatFileSize(metadataPadding)))
+	}
+	if unused, size := pa.unusedObjects(ctx, int64(info.FileSize)); len(unused) > 0 {
+		savings += size
+		info.OptimizationFindings = append(info.OptimizationFindings,
+			fmt.Sprintf("%d objects are not referenced from the document (~%s)", len(unused), formatFileSize(size)))
+	}
+
+	info.OptimizationSavings = savings
+}
+
+// flateSize returns the size of data once Flate-compressed
+func flateSize(data []byte) int {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestSpeed)
+	if err != nil {
+		return len(data)
+	}
+	w.Write(data)
+	w.Close()
+	return buf.Len()
+}
+
+// xmpPaddingLength returns the whitespace padding before the closing <?xpacket end=...?>
+func xmpPaddingLength(packet []byte) int {
+	end := bytes.LastIndex(packet, []byte("<?xpacket end"))
+	if end < 0 {
+		return 0
+	}
+	n := 0
+	for i := end - 1; i >= 0 && isContentWhitespace(packet[i]); i-- {
+		n++
+	}
+	return n
+}
+
+// unusedObjects returns the objects that cannot be reached from the trailer (Root, Info,
+// Encrypt) and their approximate size, measured from object offsets. Cross-reference and
+// object streams are structural and never counted.
+func (pa *PDFAnalyzer) unusedObjects(ctx *model.Context, fileSize int64) ([]int, int64) {
+	reachable := make(map[int]bool)
+	var queue []types.Object
+	if ctx.Root != nil {
+		queue = append(queue, *ctx.Root)
+	}
+	if ctx.Info != nil {
+		queue = append(queue, *ctx.Info)
+	}
+	if ctx.Encrypt != nil {
+		queue = append(queue, *ctx.Encrypt)
+	}
+
+	for len(queue) > 0 {
+		obj := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		switch v := obj.(type) {
+		case types.IndirectRef:
+			objNr := int(v.ObjectNumber)
+			if reachable[objNr] {
+				continue
+			}
+			reachable[objNr] = true
+			if resolved, err := ctx.Dereference(v); err == nil && resolved != nil {
+				queue = append(queue, resolved)
+			}
+		case types.Dict:
+			for _, value := range v {
+				queue = append(queue, value)
+			}
+		case types.StreamDict:
+			queue = append(queue, v.Dict)
+		case types.Array:
+			for _, value := range v {
+				queue = append(queue, value)
+			}
+		}
+	}
+
+	// Tamanho aproximado: distância até o próximo objeto no arquivo
+	var offsets []int64
+	for _, entry := range ctx.Table {
+		if entry != nil && !entry.Free && !entry.Compressed && entry.Offset != nil {
+			offsets = append(offsets, *entry.Offset)
+		}
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	sizeAt := func(offset int64) int64 {
+		i := sort.Search(len(offsets), func(i int) bool { return offsets[i] > offset })
+		if i < len(offsets) {
+			return offsets[i] - offset
+		}
+		return fileSize - offset
+	}
+
+	var unused []int
+	var size int64
+	for objNr, entry := range ctx.Table {
+		if objNr == 0 || entry == nil || entry.Free || reachable[objNr] {
+			continue
+		}
+		if isStructuralObject(ctx, objNr, entry) {
+			continue
+		}
+		unused = append(unused, objNr)
+		if !entry.Compressed && entry.Offset != nil {
+			size += sizeAt(*entry.Offset)
+		}
+	}
+	sort.Ints(unused)
+	return unused, size
+}
+
+// isStructuralObject reports whether an object is part of the file structure rather than the
+// document (cross-reference and object streams, the linearization dictionary), which is never
+// referenced from the trailer
+func isStructuralObject(ctx *model.Context, objNr int, entry *model.XRefTableEntry) bool {
+	obj := entry.Object
+	if obj == nil {
+		obj, _ = ctx.Dereference(types.IndirectRef{ObjectNumber: types.Integer(objNr)})
+	}
+	switch v := obj.(type) {
+	case types.StreamDict:
+		t := v.Dict.NameEntry("Type")
+		return t != nil && (*t == "XRef" || *t == "ObjStm")
+	case types.Dict:
+		_, linearized := v.Find("Linearized")
+		return linearized
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// boxTolerance is how far, in points, two box edges may differ and still count as equal
+const boxTolerance = 0.01
+
+// PageBox is a page boundary rectangle in points, with its corners normalized
+type PageBox struct {
+	LLX, LLY, URX, URY float64
+}
+
+// Width of the box in points
+func (b PageBox) Width() float64 { return b.URX - b.LLX }
+
+// Height of the box in points
+func (b PageBox) Height() float64 { return b.URY - b.LLY }
+
+// Equal reports whether two boxes have the same edges
+func (b PageBox) Equal(o PageBox) bool {
+	return math.Abs(b.LLX-o.LLX) <= boxTolerance && math.Abs(b.LLY-o.LLY) <= boxTolerance &&
+		math.Abs(b.URX-o.URX) <= boxTolerance && math.Abs(b.URY-o.URY) <= boxTolerance
+}
+
+// Within reports whether the box lies inside o
+func (b PageBox) Within(o PageBox) bool {
+	return b.LLX >= o.LLX-boxTolerance && b.LLY >= o.LLY-boxTolerance &&
+		b.URX <= o.URX+boxTolerance && b.URY <= o.URY+boxTolerance
+}
+
+func (b PageBox) String() string {
+	return fmt.Sprintf("%.1f x %.1f pts", b.Width(), b.Height())
+}
+
+// pageBox reads a box (/MediaBox, /CropBox, ...); nil when obj is missing or not four numbers
+func (pa *PDFAnalyzer) pageBox(ctx *model.Context, obj types.Object) *PageBox {
+	if obj == nil {
+		return nil
+	}
+	arr, err := ctx.DereferenceArray(obj)
+	if err != nil || len(arr) != 4 {
+		return nil
+	}
+	var v [4]float64
+	for i, o := range arr {
+		n, err := ctx.DereferenceNumber(o)
+		if err != nil {
+			return nil
+		}
+		v[i] = n
+	}
+	// Os cantos podem vir em qualquer ordem
+	return &PageBox{
+		LLX: math.Min(v[0], v[2]), LLY: math.Min(v[1], v[3]),
+		URX: math.Max(v[0], v[2]), URY: math.Max(v[1], v[3]),
+	}
+}
+
+// readPageBoxes fills the page boundaries of pageInfo. /MediaBox and /CropBox may be inherited
+// from the page tree; /BleedBox, /TrimBox and /ArtBox belong to the page itself.
+func (pa *PDFAnalyzer) readPageBoxes(ctx *model.Context, pageDict types.Dict, pageInfo *PageInfo) {
+	pageInfo.MediaBox = pa.pageBox(ctx, pa.inheritedPageAttribute(ctx, pageDict, "MediaBox"))
+	pageInfo.CropBox = pa.pageBox(ctx, pa.inheritedPageAttribute(ctx, pageDict, "CropBox"))
+	pageInfo.BleedBox = pa.pageBox(ctx, pageDict["BleedBox"])
+	pageInfo.TrimBox = pa.pageBox(ctx, pageDict["TrimBox"])
+	pageInfo.ArtBox = pa.pageBox(ctx, pageDict["ArtBox"])
+}
+
+// normalizeRotation brings a /Rotate value to 0, 90, 180 or 270
+func normalizeRotation(rotate int) int {
+	return (rotate%360 + 360) % 360
+}
+
+// effectiveSize returns the page size a viewer shows: the CropBox, or the MediaBox when there is
+// none, with width and height swapped for pages rotated by 90 or 270 degrees
+func effectiveSize(page PageInfo) (width, height float64) {
+	width, height = page.Width, page.Height
+	if page.CropBox != nil {
+		width, height = page.CropBox.Width(), page.CropBox.Height()
+	}
+	if page.Rotation%180 != 0 {
+		width, height = height, width
+	}
+	return width, height
+}
+
+// pageBoxIssues lists what is wrong with the boundaries of a page: a CropBox reaching beyond
+// the MediaBox, which viewers clip, and, in a file meant for print, a missing TrimBox
+func pageBoxIssues(page PageInfo, printIntended bool) []string {
+	var issues []string
+	if page.MediaBox != nil && page.CropBox != nil && !page.CropBox.Within(*page.MediaBox) {
+		issues = append(issues, "CropBox extends beyond MediaBox")
+	}
+	if printIntended && page.TrimBox == nil {
+		issues = append(issues, "no TrimBox in a print-intended file")
+	}
+	return issues
+}
+
+// hasPDFXOutputIntent reports whether the document declares a PDF/X output intent
+// (/OutputIntents with /S /GTS_PDFX), i.e. it is meant for print production
+func (pa *PDFAnalyzer) hasPDFXOutputIntent(ctx *model.Context) bool {
+	if ctx == nil || ctx.RootDict == nil {
+		return false
+	}
+	intents, err := ctx.DereferenceArray(ctx.RootDict["OutputIntents"])
+	if err != nil {
+		return false
+	}
+	for _, obj := range intents {
+		if intent, err := ctx.DereferenceDict(obj); err == nil && intent != nil {
+			if s := intent.NameEntry("S"); s != nil && *s == "GTS_PDFX" {
+				return true
+			}
+		}
+	}
+	return false
+}
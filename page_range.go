@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// defaultPagesShown is how many pages the report lists when no range is requested
+const defaultPagesShown = 5
+
+// pageRange is the --pages flag: "all", a single page ("7") or a range ("10-20", "10-").
+// The zero value means the default listing of the first pages.
+type pageRange struct {
+	First int // first page to list, 1-based; 0 when no range was given
+	Last  int // last page to list; 0 means up to the last page
+}
+
+func (r *pageRange) String() string {
+	switch {
+	case r.First == 0:
+		return ""
+	case r.First == 1 && r.Last == 0:
+		return "all"
+	case r.Last == 0:
+		return fmt.Sprintf("%d-", r.First)
+	case r.First == r.Last:
+		return strconv.Itoa(r.First)
+	}
+	return fmt.Sprintf("%d-%d", r.First, r.Last)
+}
+
+func (r *pageRange) Set(value string) error {
+	value = strings.TrimSpace(value)
+	if strings.EqualFold(value, "all") {
+		*r = pageRange{First: 1}
+		return nil
+	}
+
+	from, to, isRange := strings.Cut(value, "-")
+	first, err := strconv.Atoi(strings.TrimSpace(from))
+	if err != nil || first < 1 {
+		return fmt.Errorf("invalid page range %q: expected all, N, N-M or N-", value)
+	}
+	last := first
+	if isRange {
+		last = 0
+		if to = strings.TrimSpace(to); to != "" {
+			last, err = strconv.Atoi(to)
+			if err != nil || last < first {
+				return fmt.Errorf("invalid page range %q: expected all, N, N-M or N-", value)
+			}
+		}
+	}
+	*r = pageRange{First: first, Last: last}
+	return nil
+}
+
+// pagesToShow returns the pages the report lists for the requested range
+func (r pageRange) pagesToShow(pages []PageInfo) []PageInfo {
+	if r.First == 0 {
+		if len(pages) > defaultPagesShown {
+			return pages[:defaultPagesShown]
+		}
+		return pages
+	}
+	var shown []PageInfo
+	for _, page := range pages {
+		if page.Number >= r.First && (r.Last == 0 || page.Number <= r.Last) {
+			shown = append(shown, page)
+		}
+	}
+	return shown
+}
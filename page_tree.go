@@ -0,0 +1,39 @@
+package main
+
+import (
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// maxPageTreeDepth limits how far up the /Parent chain inherited attributes are looked for
+const maxPageTreeDepth = 64
+
+// inheritedPageAttribute returns an inheritable page attribute (/MediaBox, /CropBox, /Rotate or
+// /Resources): the page's own entry or, when it has none, the one of the nearest /Pages node
+// up the /Parent chain. It returns nil when no node defines it.
+func (pa *PDFAnalyzer) inheritedPageAttribute(ctx *model.Context, pageDict types.Dict, key string) types.Object {
+	visited := make(map[int]bool)
+	node := pageDict
+	for depth := 0; node != nil && depth < maxPageTreeDepth; depth++ {
+		if obj, found := node.Find(key); found && obj != nil {
+			return obj
+		}
+		parentObj, found := node.Find("Parent")
+		if !found || parentObj == nil {
+			return nil
+		}
+		// Uma árvore corrompida pode ter ciclos
+		if ref, ok := parentObj.(types.IndirectRef); ok {
+			if visited[int(ref.ObjectNumber)] {
+				return nil
+			}
+			visited[int(ref.ObjectNumber)] = true
+		}
+		parent, err := ctx.DereferenceDict(parentObj)
+		if err != nil {
+			return nil
+		}
+		node = parent
+	}
+	return nil
+}
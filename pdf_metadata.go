@@ -2,24 +2,30 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
-	"github.com/pdfcpu/pdfcpu/pkg/api"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
 )
 
-// analyzePDFCPU performs PDF analysis using the pdfcpu library
-func (pa *PDFAnalyzer) analyzePDFCPU(filePath string, info *PDFInfo) error {
-	ctx, err := api.ReadContextFile(filePath)
+// analyzePDFCPU performs PDF analysis using the pdfcpu library. pdfcpu panics on many damaged
+// files instead of failing; the panic is returned as an error so the byte-level fallback runs.
+func (pa *PDFAnalyzer) analyzePDFCPU(filePath string, data []byte, info *PDFInfo) (err error) {
+	defer recoverParserPanic(&err)
+
+	ctx, err := pa.readContext(data)
 	if err != nil {
 		return err
 	}
 
 	// Extract PDF metadata
+	info.Completeness.Metadata = completenessFull
 	pa.extractMetadata(ctx, info)
 
 	// Extract technical information
 	pa.extractTechnicalInfo(ctx, info)
+	pa.analyzeLinearization(data, info)
 
 	// Extract structure information
 	pa.extractStructureInfo(ctx, info)
@@ -32,18 +38,62 @@ func (pa *PDFAnalyzer) analyzePDFCPU(filePath string, info *PDFInfo) error {
 	// Analyze pages
 	pa.analyzePages(ctx, info)
 
+	// Fonts referenced by the pages
+	pa.extractFonts(ctx, info)
+
+	// Version in effect after each incremental update; signatures are mapped to these revisions
+	pa.analyzeRevisionVersions(data, info)
+
 	// Analyze digital signatures
-	pa.analyzeDigitalSignatures(filePath, ctx, info)
+	pa.analyzeDigitalSignatures(filePath, data, ctx, info)
+
+	// Look for references to missing objects
+	pa.findDanglingReferences(ctx, info)
+
+	// Survey stream filter chains
+	pa.analyzeFilterChains(ctx, info)
+
+	// Estimate what an optimizer could save
+	pa.analyzeOptimization(ctx, info)
+
+	// Streams with near-random content
+	if pa.ComputeEntropy {
+		pa.analyzeStreamEntropy(ctx, info)
+	}
+
+	// Count text directly in the content streams, for the cross-check with ledongthuc
+	if pa.CrossCheckText {
+		info.ContentStreamTextLength = pa.countContentStreamText(ctx)
+	}
+
+	// PDFs anexados, analisados recursivamente a partir do contexto já lido
+	if pa.RecurseEmbedded {
+		pa.analyzeEmbeddedPDFs(ctx, data, info)
+	}
 
 	return nil
 }
 
-// extractMetadata extracts PDF metadata from the Info dictionary
+// standardInfoKeys lists the Info dictionary entries defined by the PDF specification
+var standardInfoKeys = map[string]bool{
+	"Title":        true,
+	"Author":       true,
+	"Subject":      true,
+	"Keywords":     true,
+	"Creator":      true,
+	"Producer":     true,
+	"CreationDate": true,
+	"ModDate":      true,
+	"Trapped":      true,
+}
+
+// extractMetadata extracts PDF metadata from the Info dictionary and the XMP stream
 func (pa *PDFAnalyzer) extractMetadata(ctx *model.Context, info *PDFInfo) {
 	if ctx.XRefTable != nil && ctx.XRefTable.Info != nil {
 		infoObject, err := ctx.Dereference(*ctx.XRefTable.Info)
 		if err != nil {
-			fmt.Printf("Warning: could not dereference Info dictionary: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Warning: could not dereference Info dictionary: %v\n", err)
+			info.Completeness.Metadata = completenessPartial
 		} else {
 			if actualInfoDict, ok := infoObject.(types.Dict); ok {
 				info.Title = getStringFromDict(actualInfoDict, "Title")
@@ -54,33 +104,114 @@ func (pa *PDFAnalyzer) extractMetadata(ctx *model.Context, info *PDFInfo) {
 				info.Producer = getStringFromDict(actualInfoDict, "Producer")
 				info.CreationDate = getStringFromDict(actualInfoDict, "CreationDate")
 				info.ModDate = getStringFromDict(actualInfoDict, "ModDate")
+				info.Trapped = trappedState(actualInfoDict)
+
+				// Entradas não padronizadas (metadados personalizados)
+				for key := range actualInfoDict {
+					if standardInfoKeys[key] {
+						continue
+					}
+					if value := getStringFromDict(actualInfoDict, key); value != "" {
+						if info.CustomMetadata == nil {
+							info.CustomMetadata = make(map[string]string)
+						}
+						info.CustomMetadata[key] = value
+					}
+				}
 			} else {
-				fmt.Printf("Warning: Info object is not a dictionary, but rather type %T\n", infoObject)
+				fmt.Fprintf(os.Stderr, "Warning: Info object is not a dictionary, but rather type %T\n", infoObject)
+				info.Completeness.Metadata = completenessPartial
 			}
 		}
 	}
+
+	// XMP metadata stream referenced by the catalog
+	pa.extractXMPMetadata(ctx, info)
+
+	// /Trapped ausente equivale a Unknown; o XMP pode declará-lo no lugar do Info
+	if info.Trapped == "" {
+		info.Trapped = normalizeTrapped(info.XMPProperties["pdf:Trapped"])
+	}
+
+	// Consistência de fusos horários entre as datas do Info e do XMP
+	checkDateTimezones(info)
+	parseDocumentDates(info)
+	setSoftwareFamilies(info)
+
+	info.HasNoMetadata = !hasStandardMetadata(info)
+}
+
+// hasStandardMetadata reports whether the Info dictionary or the XMP packet carries any of the
+// standard document fields (title, author, dates, ...). /Trapped alone doesn't count.
+func hasStandardMetadata(info *PDFInfo) bool {
+	for _, value := range []string{info.Title, info.Author, info.Subject, info.Keywords,
+		info.Creator, info.Producer, info.CreationDate, info.ModDate} {
+		if value != "" {
+			return true
+		}
+	}
+	for prop, key := range xmpStandardKeys {
+		if key != "trapped" && info.XMPProperties[prop] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// trappedState reads the Info /Trapped entry, which should be a name but is sometimes written
+// as a string or boolean. It returns "" when the entry is absent.
+func trappedState(infoDict types.Dict) string {
+	obj, found := infoDict.Find("Trapped")
+	if !found || obj == nil {
+		return ""
+	}
+	if b, ok := obj.(types.Boolean); ok {
+		if bool(b) {
+			return "True"
+		}
+		return "False"
+	}
+	return normalizeTrapped(getStringFromDict(infoDict, "Trapped"))
+}
+
+// normalizeTrapped maps a trapped value to "True", "False" or "Unknown"
+func normalizeTrapped(value string) string {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true":
+		return "True"
+	case "false":
+		return "False"
+	}
+	return "Unknown"
 }
 
 // extractTechnicalInfo extracts technical PDF information
 func (pa *PDFAnalyzer) extractTechnicalInfo(ctx *model.Context, info *PDFInfo) {
 	if ctx.HeaderVersion != nil {
-		// HeaderVersion contém apenas a parte decimal (3 para 1.3, 4 para 1.4, etc.)
-		// Adiciona o "1." na frente para formar a versão completa
-		info.PDFVersion = fmt.Sprintf("1.%d", *ctx.HeaderVersion)
+		info.HeaderVersion = pdfVersionString(*ctx.HeaderVersion)
+	}
+	// O /Version do catálogo prevalece sobre o cabeçalho quando é maior
+	info.PDFVersion = info.HeaderVersion
+	if cv := catalogVersion(ctx); cv != "" && compareVersions(cv, info.HeaderVersion) > 0 {
+		info.PDFVersion = cv
+		info.VersionOverridden = true
 	}
 	info.PageCount = ctx.PageCount
 	info.IsEncrypted = ctx.E != nil
+	pa.extractFileIdentifier(ctx, info)
 
-	// Verificar linearização através de propriedades do contexto
-	info.IsLinearized = ctx.LinearizationObjs != nil
+	// pdfcpu marca o arquivo como linearizado quando encontra o dicionário /Linearized;
+	// LinearizationObjs nunca é nil e não serve para isso
+	info.IsLinearized = ctx.Read != nil && ctx.Read.Linearized
 }
 
 // extractStructureInfo extracts structural information from the PDF
 func (pa *PDFAnalyzer) extractStructureInfo(ctx *model.Context, info *PDFInfo) {
 	if ctx.RootDict != nil {
-		// Verificar se tem formulários
-		if entry := ctx.RootDict.DictEntry("AcroForm"); entry != nil {
+		// Verificar se tem formulários (AcroForm costuma ser uma referência indireta)
+		if entry, found := ctx.RootDict.Find("AcroForm"); found && entry != nil {
 			info.HasForms = true
+			pa.extractFormInfo(ctx, info)
 		}
 
 		// Verificar JavaScript
@@ -107,5 +238,58 @@ func (pa *PDFAnalyzer) extractStructureInfo(ctx *model.Context, info *PDFInfo) {
 				info.IsTagged = true
 			}
 		}
+
+		// Tipos de estrutura e mapeamento de papéis
+		mcids := pa.analyzeStructureTree(ctx, info)
+		info.TaggingInconsistent = info.IsTagged != info.HasStructTree
+
+		// Conteúdo desenhado fora da árvore de estrutura
+		if info.IsTagged && mcids != nil {
+			pa.analyzeUntaggedContent(ctx, info, mcids)
+		}
+
+		// Camadas (conteúdo opcional) e quais ficam ocultas ao abrir
+		pa.extractLayers(ctx, info)
+
+		// Scripts do documento, das páginas, das anotações e dos campos
+		pa.analyzeJavaScript(ctx, info)
+
+		// Ações que executam programas, abrem URLs ou arquivos externos, ou enviam dados
+		pa.analyzeActions(ctx, info)
+
+		// Título exibido na barra de título do leitor
+		pa.extractDisplayTitle(ctx, info)
+
+		// Língua declarada do documento; documentos acessíveis devem declará-la
+		info.DeclaredLanguage = getStringFromDict(ctx.RootDict, "Lang")
+		info.LanguageMissing = info.IsTagged && info.DeclaredLanguage == ""
+
+		// Requisitos estruturais de acessibilidade e a declaração PDF/UA do XMP
+		pa.analyzeAccessibility(info)
+	}
+}
+
+// extractDisplayTitle determines the title a viewer shows in its title bar. Without the
+// ViewerPreferences /DisplayDocTitle flag viewers show the file name, even if the document has a title.
+func (pa *PDFAnalyzer) extractDisplayTitle(ctx *model.Context, info *PDFInfo) {
+	if obj, found := ctx.RootDict.Find("ViewerPreferences"); found && obj != nil {
+		if vp, err := ctx.DereferenceDict(obj); err == nil && vp != nil {
+			if display := vp.BooleanEntry("DisplayDocTitle"); display != nil {
+				info.DisplayDocTitle = *display
+			}
+		}
+	}
+
+	info.DisplayTitle = info.FileName
+	if title := documentTitle(info); title != "" && info.DisplayDocTitle {
+		info.DisplayTitle = title
+	}
+}
+
+// documentTitle returns the document title from the Info dictionary, falling back to XMP dc:title
+func documentTitle(info *PDFInfo) string {
+	if info.Title != "" {
+		return info.Title
 	}
+	return info.XMPProperties["dc:title"]
 }
@@ -3,45 +3,60 @@ package main
 import (
 	"io"
 	"strings"
-
-	"github.com/ledongthuc/pdf"
 )
 
 // analyzeLedongthuc performs PDF analysis using the ledongthuc/pdf library
-func (pa *PDFAnalyzer) analyzeLedongthuc(filePath string, info *PDFInfo) error {
-	f, r, err := pdf.Open(filePath)
+func (pa *PDFAnalyzer) analyzeLedongthuc(data []byte, info *PDFInfo) (err error) {
+	// Páginas malformadas derrubam a extração de texto com pânico
+	defer recoverParserPanic(&err)
+
+	r, err := pa.textReader(data)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 
 	totalTextLength := 0
-	var fontsUsed []string
-	imagesCount := 0
+	info.TotalWordCount, info.TotalLineCount = 0, 0
+	info.Completeness.Text = completenessFull
+	var sample strings.Builder // texto para detectar a língua
 
 	// Extrair texto de todas as páginas
 	for i := 1; i <= r.NumPage(); i++ {
 		page := r.Page(i)
 		if page.V.IsNull() {
+			info.Completeness.Text = completenessPartial
 			continue
 		}
 		
 		text, err := page.GetPlainText(nil)
 		if err != nil {
+			info.Completeness.Text = completenessPartial
 			continue
 		}
 		
 		textLen := len(strings.TrimSpace(text))
 		totalTextLength += textLen
+		words, lines := countWords(text), countLines(text)
+		info.TotalWordCount += words
+		info.TotalLineCount += lines
+		if sample.Len() < languageSampleSize {
+			sample.WriteString(text)
+			sample.WriteByte('\n')
+		}
 		
 		// Atualizar informação da página se ela existir
 		if i-1 < len(info.Pages) {
 			info.Pages[i-1].TextLength = textLen
+			info.Pages[i-1].WordCount = words
+			info.Pages[i-1].LineCount = lines
+			if pa.KeepText {
+				info.Pages[i-1].Text = text
+			}
 		}
 	}	
 	info.TotalTextLength = totalTextLength
-	info.FontsUsed = fontsUsed
-	info.ImagesCount = imagesCount
+	info.DetectedLanguage, info.LanguageConfidence = detectLanguage(sample.String())
+	info.LanguageMismatch = languagesDiffer(info.DeclaredLanguage, info.DetectedLanguage)
 	
 	return nil
 }
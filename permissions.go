@@ -9,8 +9,25 @@ func (pa *PDFAnalyzer) analyzePermissions(ctx *model.Context, info *PDFInfo) {
 	encDict, err := ctx.EncryptDict()
 	if err != nil {
 		// Se não conseguir obter o dicionário de criptografia, retornar
+		info.Completeness.Permissions = completenessFailed
 		return
 	}
+	info.Completeness.Permissions = completenessFull
+
+	// Handler de segurança: as permissões /P só valem para o handler Standard
+	var filter, subFilter string
+	if name := encDict.NameEntry("Filter"); name != nil {
+		filter = *name
+	}
+	if name := encDict.NameEntry("SubFilter"); name != nil {
+		subFilter = *name
+	}
+	setSecurityHandler(info, filter, subFilter)
+	if !isStandardSecurityHandler(info) {
+		info.Completeness.Permissions = completenessPartial
+	}
+	pa.analyzeEncryptionAlgorithm(ctx, encDict, info)
+	info.PermissionsEnforced = permissionsEnforced(info, encDict)
 
 	// Verificar entradas U e O (senhas de usuário e proprietário)
 	if _, foundU := encDict.Find("U"); foundU {
@@ -37,6 +54,7 @@ func (pa *PDFAnalyzer) analyzePermissions(ctx *model.Context, info *PDFInfo) {
 		// Valores padrão se P não for encontrado ou for nulo.
 		// A especificação PDF pode ditar padrões restritivos se P estiver ausente em um PDF criptografado.
 		// Para simplificar, definimos como true, mas isso pode não ser preciso para todos os casos.
+		info.Completeness.Permissions = completenessPartial
 		info.PrintAllowed = true
 		info.ModifyAllowed = true
 		info.CopyAllowed = true
@@ -46,4 +64,7 @@ func (pa *PDFAnalyzer) analyzePermissions(ctx *model.Context, info *PDFInfo) {
 		info.AssembleAllowed = true
 		info.PrintHighQualityAllowed = true
 	}
+
+	// O documento foi aberto: sem --password, a senha de usuário é vazia
+	info.SecurityWarnings = securityWarnings(info, pa.Password == "")
 }
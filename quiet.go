@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// quietWriter drops the decoration of the text report (--quiet): the title and section
+// banners, the ==== and ---- rules, emoji, blank lines and the footer. The data lines pass
+// through unchanged, so the output can be searched with grep or awk. Lines are held until
+// they are complete; Flush writes a last unterminated line.
+type quietWriter struct {
+	w       io.Writer
+	partial []byte
+}
+
+func (qw *quietWriter) Write(p []byte) (int, error) {
+	qw.partial = append(qw.partial, p...)
+	for {
+		i := bytes.IndexByte(qw.partial, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := quietLine(string(qw.partial[:i]))
+		qw.partial = qw.partial[i+1:]
+		if line == "" {
+			continue
+		}
+		if _, err := io.WriteString(qw.w, line+"\n"); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Flush writes what is left of an unterminated last line
+func (qw *quietWriter) Flush() error {
+	line := quietLine(string(qw.partial))
+	qw.partial = nil
+	if line == "" {
+		return nil
+	}
+	_, err := io.WriteString(qw.w, line+"\n")
+	return err
+}
+
+// writeQuietly calls write with w or, when quiet, with a quietWriter over w
+func writeQuietly(w io.Writer, quiet bool, write func(w io.Writer)) {
+	if !quiet {
+		write(w)
+		return
+	}
+	qw := &quietWriter{w: w}
+	write(qw)
+	qw.Flush()
+}
+
+// isDecoration reports whether r is an emoji or other pictograph, or the selector that turns
+// a symbol into its emoji form
+func isDecoration(r rune) bool {
+	return unicode.Is(unicode.So, r) || r == '\uFE0F'
+}
+
+// quietLine returns a report line without its decoration, or "" when the whole line is
+// decoration
+func quietLine(line string) string {
+	trimmed := strings.TrimSpace(line)
+	switch {
+	case strings.Trim(trimmed, "=-") == "":
+		return ""
+	case trimmed == "PDF ANALYSIS REPORT", strings.HasPrefix(trimmed, "Analysis completed at:"):
+		return ""
+	}
+	// Títulos de seção começam por um emoji, sem recuo; avisos recuados ficam
+	if first, _ := utf8.DecodeRuneInString(line); isDecoration(first) {
+		return ""
+	}
+	if strings.IndexFunc(line, isDecoration) >= 0 {
+		indent := line[:len(line)-len(strings.TrimLeft(line, " "))]
+		line = indent + strings.TrimSpace(strings.Map(func(r rune) rune {
+			if isDecoration(r) {
+				return -1
+			}
+			return r
+		}, line))
+	}
+	return strings.TrimPrefix(strings.TrimRight(line, " "), ">>> ")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// maxDanglingExamples limits how many dangling references are listed in the report
+const maxDanglingExamples = 5
+
+// findDanglingReferences scans every object in the cross-reference table for indirect
+// references to objects that do not exist (or are free), counting each missing object once
+func (pa *PDFAnalyzer) findDanglingReferences(ctx *model.Context, info *PDFInfo) {
+	if ctx.XRefTable == nil || ctx.XRefTable.Table == nil {
+		return
+	}
+
+	objNrs := make([]int, 0, len(ctx.XRefTable.Table))
+	for objNr := range ctx.XRefTable.Table {
+		objNrs = append(objNrs, objNr)
+	}
+	sort.Ints(objNrs)
+
+	missing := make(map[types.IndirectRef]bool)
+	var walk func(obj types.Object, owner int, depth int)
+	walk = func(obj types.Object, owner int, depth int) {
+		if obj == nil || depth > 64 {
+			return
+		}
+		switch v := obj.(type) {
+		case types.IndirectRef:
+			if missing[v] || !pa.isDanglingReference(ctx, v) {
+				return
+			}
+			missing[v] = true
+			if len(info.DanglingReferenceExamples) < maxDanglingExamples {
+				info.DanglingReferenceExamples = append(info.DanglingReferenceExamples,
+					fmt.Sprintf("%d %d R (referenced from object %d)", v.ObjectNumber, v.GenerationNumber, owner))
+			}
+		case types.Dict:
+			for _, value := range v {
+				walk(value, owner, depth+1)
+			}
+		case types.StreamDict:
+			walk(v.Dict, owner, depth+1)
+		case *types.StreamDict:
+			walk(v.Dict, owner, depth+1)
+		case types.Array:
+			for _, value := range v {
+				walk(value, owner, depth+1)
+			}
+		}
+	}
+
+	for _, objNr := range objNrs {
+		entry := ctx.XRefTable.Table[objNr]
+		if entry == nil || entry.Free {
+			continue
+		}
+		genNr := 0
+		if entry.Generation != nil {
+			genNr = *entry.Generation
+		}
+		obj, err := ctx.Dereference(types.IndirectRef{
+			ObjectNumber:     types.Integer(objNr),
+			GenerationNumber: types.Integer(genNr),
+		})
+		if err != nil {
+			continue
+		}
+		walk(obj, objNr, 0)
+	}
+
+	info.DanglingReferenceCount = len(missing)
+}
+
+// isDanglingReference reports whether an indirect reference points to a missing or free object
+func (pa *PDFAnalyzer) isDanglingReference(ctx *model.Context, ref types.IndirectRef) bool {
+	entry, found := ctx.FindTableEntry(int(ref.ObjectNumber), int(ref.GenerationNumber))
+	if !found || entry == nil || entry.Free {
+		return true
+	}
+	if entry.Object != nil {
+		return false
+	}
+	// Objetos comprimidos podem ser carregados sob demanda
+	obj, err := ctx.Dereference(ref)
+	return err != nil || obj == nil
+}
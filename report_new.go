@@ -2,244 +2,902 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
 
-// PrintReport generates and prints a comprehensive PDF analysis report
-func (pa *PDFAnalyzer) PrintReport(info *PDFInfo) {
-	fmt.Println("=" + strings.Repeat("=", 80))
-	fmt.Println("                        PDF ANALYSIS REPORT")
-	fmt.Println("=" + strings.Repeat("=", 80))
+// errWriter remembers the first write error so the report can be written without checking
+// every line; later writes are skipped
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) Write(p []byte) (int, error) {
+	if ew.err != nil {
+		return 0, ew.err
+	}
+	n, err := ew.w.Write(p)
+	ew.err = err
+	return n, err
+}
+
+// PrintReport writes a comprehensive PDF analysis report to w, returning the first write error
+func (pa *PDFAnalyzer) PrintReport(w io.Writer, info *PDFInfo) error {
+	ew := &errWriter{w: w}
+	writeQuietly(ew, pa.Quiet, func(w io.Writer) {
+		pa.writeReport(w, info)
+	})
+	return ew.err
+}
+
+// PrintSignatureReport writes only the digital signatures section (--verify-only), preceded by
+// the file it belongs to, returning the first write error
+func (pa *PDFAnalyzer) PrintSignatureReport(w io.Writer, info *PDFInfo) error {
+	ew := &errWriter{w: w}
+	writeQuietly(ew, pa.Quiet, func(w io.Writer) {
+		fmt.Fprintf(w, "File: %s\n", info.FilePath)
+		pa.printDigitalSignatures(w, info)
+	})
+	return ew.err
+}
+
+// writeReport writes the report sections, followed by the reports of the embedded PDFs
+func (pa *PDFAnalyzer) writeReport(w io.Writer, info *PDFInfo) {
+	fmt.Fprintln(w, "=" + strings.Repeat("=", 80))
+	fmt.Fprintln(w, "                        PDF ANALYSIS REPORT")
+	fmt.Fprintln(w, "=" + strings.Repeat("=", 80))
 
 	// File information
-	pa.printFileInformation(info)
+	pa.printFileInformation(w, info)
 
 	// Document information
-	pa.printDocumentMetadata(info)
+	pa.printDocumentMetadata(w, info)
 
 	// Technical information
-	pa.printTechnicalInformation(info)
+	pa.printTechnicalInformation(w, info)
 
 	// Security information
 	if info.IsEncrypted {
-		pa.printSecurityInformation(info)
+		pa.printSecurityInformation(w, info)
+	}
+
+	if len(info.SecurityWarnings) > 0 {
+		pa.printSecurityWarnings(w, info)
+	}
+
+	if len(info.SuspiciousActions) > 0 {
+		pa.printSuspiciousActions(w, info)
+	}
+
+	pa.printRiskAssessment(w, info)
+
+	// Form information
+	if info.HasForms {
+		pa.printFormInformation(w, info)
+	}
+
+	if len(info.FormFields) > 0 {
+		pa.printFormFields(w, info)
 	}
 
 	// Content information
-	pa.printContentInformation(info)
+	pa.printContentInformation(w, info)
 
 	// Page information
 	if len(info.Pages) > 0 {
-		pa.printPageInformation(info)
+		pa.printPageInformation(w, info)
+	}
+
+	// Annotations
+	if len(info.Annotations) > 0 {
+		pa.printAnnotations(w, info)
+	}
+
+	// Layers
+	if len(info.Layers) > 0 {
+		pa.printLayers(w, info)
 	}
 
 	// Bookmarks
 	if len(info.Bookmarks) > 0 {
-		pa.printBookmarks(info)
+		pa.printBookmarks(w, info)
 	}
 
 	// Attachments
 	if len(info.Attachments) > 0 {
-		pa.printAttachments(info)
+		pa.printAttachments(w, info)
+	}
+
+	// Embedded PDFs
+	if len(info.EmbeddedPDFs) > 0 {
+		pa.printEmbeddedPDFs(w, info)
 	}
 
 	// Digital signatures - always visible section
-	pa.printDigitalSignatures(info)
+	pa.printDigitalSignatures(w, info)
+
+	// Document history
+	if len(info.Revisions) > 1 || info.HasDigitalSignatures {
+		pa.printDocumentHistory(w, info)
+	}
 
 	// Footer
-	pa.printReportFooter()
+	pa.printReportFooter(w)
+
+	// Relatórios completos dos PDFs anexados
+	for _, embedded := range info.EmbeddedPDFs {
+		if embedded.Info != nil {
+			fmt.Fprintf(w, "\n>>> Embedded PDF: %s\n", embedded.Info.FilePath)
+			pa.writeReport(w, embedded.Info)
+		}
+	}
 }
 
 // printFileInformation prints basic file information
-func (pa *PDFAnalyzer) printFileInformation(info *PDFInfo) {
-	fmt.Println("\n📁 FILE INFORMATION")
-	fmt.Println(strings.Repeat("-", 50))
-	fmt.Printf("File name: %s\n", info.FileName)
-	fmt.Printf("Path: %s\n", info.FilePath)
-	fmt.Printf("Size: %s (%d bytes)\n", info.FileSizeHuman, info.FileSize)
-	fmt.Printf("Last modified: %s\n", info.LastModified.Format("2006-01-02 15:04:05"))
-	fmt.Printf("MD5: %s\n", info.MD5Hash)
-	fmt.Printf("SHA256: %s\n", info.SHA256Hash)
+func (pa *PDFAnalyzer) printFileInformation(w io.Writer, info *PDFInfo) {
+	fmt.Fprintln(w, "\n📁 FILE INFORMATION")
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+	fmt.Fprintf(w, "File name: %s\n", info.FileName)
+	fmt.Fprintf(w, "Path: %s\n", info.FilePath)
+	fmt.Fprintf(w, "Size: %s (%d bytes)\n", info.FileSizeHuman, info.FileSize)
+	fmt.Fprintf(w, "Last modified: %s\n", info.LastModified.Format("2006-01-02 15:04:05"))
+	for _, alg := range hashAlgorithms {
+		if digest, ok := info.Hashes[alg.Name]; ok {
+			fmt.Fprintf(w, "%s: %s\n", alg.Label, digest)
+		}
+	}
 }
 
 // printDocumentMetadata prints PDF document metadata
-func (pa *PDFAnalyzer) printDocumentMetadata(info *PDFInfo) {
-	fmt.Println("\n📄 DOCUMENT METADATA")
-	fmt.Println(strings.Repeat("-", 50))
-	printIfNotEmpty("Title", info.Title)
-	printIfNotEmpty("Author", info.Author)
-	printIfNotEmpty("Subject", info.Subject)
-	printIfNotEmpty("Keywords", info.Keywords)
-	printIfNotEmpty("Creator", info.Creator)
-	printIfNotEmpty("Producer", info.Producer)
-	printIfNotEmpty("Creation date", info.CreationDate)
-	printIfNotEmpty("Modification date", info.ModDate)
+func (pa *PDFAnalyzer) printDocumentMetadata(w io.Writer, info *PDFInfo) {
+	fmt.Fprintln(w, "\n📄 DOCUMENT METADATA")
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+	if info.HasNoMetadata {
+		fmt.Fprintln(w, "Document contains no metadata (no Info dictionary fields and no XMP)")
+	}
+	printIfNotEmpty(w, "Title", info.Title)
+	printIfNotEmpty(w, "Author", info.Author)
+	printIfNotEmpty(w, "Subject", info.Subject)
+	printIfNotEmpty(w, "Keywords", info.Keywords)
+	printIfNotEmpty(w, "Creator", info.Creator)
+	printIfNotEmpty(w, "Creator family", info.CreatorFamily)
+	printIfNotEmpty(w, "Producer", info.Producer)
+	printIfNotEmpty(w, "Producer family", info.ProducerFamily)
+	printDocumentDate(w, "Creation date", info.CreationDate, info.CreationDateParsed)
+	printDocumentDate(w, "Modification date", info.ModDate, info.ModDateParsed)
+	for _, issue := range info.DateTimezoneIssues {
+		fmt.Fprintf(w, "Warning: %s\n", issue)
+	}
+	printIfNotEmpty(w, "Trapped", info.Trapped)
+	if info.XMPPresent {
+		fmt.Fprintln(w, "XMP metadata: Yes")
+	}
+	if len(info.XMPFields) > 0 {
+		fmt.Fprintf(w, "Taken from XMP: %s\n", strings.Join(info.XMPFields, ", "))
+	}
+	printIfNotEmpty(w, "Display title", info.DisplayTitle)
+	if documentTitle(info) != "" && !info.DisplayDocTitle {
+		fmt.Fprintln(w, "Warning: document has a title but it won't be displayed (DisplayDocTitle not set)")
+	}
+}
+
+// printDocumentDate prints a document date in a readable form, or verbatim when it could not
+// be parsed
+func printDocumentDate(w io.Writer, label, raw string, parsed time.Time) {
+	if parsed.IsZero() {
+		printIfNotEmpty(w, label, raw)
+		return
+	}
+	fmt.Fprintf(w, "%s: %s\n", label, formatPDFDate(parsed))
 }
 
 // printTechnicalInformation prints technical PDF information
-func (pa *PDFAnalyzer) printTechnicalInformation(info *PDFInfo) {
-	fmt.Println("\n⚙️  TECHNICAL INFORMATION")
-	fmt.Println(strings.Repeat("-", 50))
-	fmt.Printf("PDF version: %s\n", info.PDFVersion)
-	fmt.Printf("Number of pages: %d\n", info.PageCount)
-	fmt.Printf("Is encrypted: %s\n", boolToYesNo(info.IsEncrypted))
-	fmt.Printf("Is linearized: %s\n", boolToYesNo(info.IsLinearized))
-	fmt.Printf("Is tagged (accessible): %s\n", boolToYesNo(info.IsTagged))
-	fmt.Printf("Has bookmarks: %s\n", boolToYesNo(info.HasBookmarks))
-	fmt.Printf("Has attachments: %s\n", boolToYesNo(info.HasAttachments))
-	fmt.Printf("Has forms: %s\n", boolToYesNo(info.HasForms))
-	fmt.Printf("Has JavaScript: %s\n", boolToYesNo(info.HasJavaScript))
-	fmt.Printf("Has annotations: %s\n", boolToYesNo(info.HasAnnotations))
-	fmt.Printf("Has digital signatures: %s\n", boolToYesNo(info.HasDigitalSignatures))
+func (pa *PDFAnalyzer) printTechnicalInformation(w io.Writer, info *PDFInfo) {
+	fmt.Fprintln(w, "\n⚙️  TECHNICAL INFORMATION")
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+	printCompletenessWarning(w, info.Completeness)
+	if info.IsTruncated {
+		fmt.Fprintf(w, "Warning: file appears truncated — %s\n", strings.Join(info.TruncationReasons, " / "))
+	}
+	if info.FallbackParsing {
+		fmt.Fprintln(w, "Analysis source: fallback parsing (lower confidence; the main parser could not read this file)")
+	}
+	fmt.Fprintf(w, "PDF version: %s\n", info.PDFVersion)
+	if info.VersionOverridden {
+		fmt.Fprintf(w, "Note: the header declares %s; the catalog /Version overrides it\n", info.HeaderVersion)
+	}
+	if info.RevisionCount > 1 {
+		fmt.Fprintf(w, "Revisions: %d\n", info.RevisionCount)
+	}
+	if history := versionHistory(info.RevisionVersions); history != "" {
+		fmt.Fprintf(w, "Version history: %s\n", history)
+	}
+	printIfNotEmpty(w, "Document ID", info.DocumentID)
+	if info.RevisionID != "" {
+		changed := "same as document ID: not modified since creation"
+		if info.IDChanged {
+			changed = "differs from document ID: modified after creation"
+		}
+		fmt.Fprintf(w, "Revision ID: %s (%s)\n", info.RevisionID, changed)
+	}
+	fmt.Fprintf(w, "Number of pages: %d\n", info.PageCount)
+	fmt.Fprintf(w, "Is encrypted: %s\n", boolToYesNo(info.IsEncrypted))
+	switch {
+	case !info.IsLinearized:
+		fmt.Fprintln(w, "Is linearized: No")
+	case info.LinearizationValid:
+		fmt.Fprintln(w, "Is linearized: Yes (valid)")
+	default:
+		fmt.Fprintf(w, "Is linearized: Yes (corrupt: %s)\n", strings.Join(info.LinearizationIssues, "; "))
+	}
+	fmt.Fprintf(w, "Is tagged (accessible): %s\n", boolToYesNo(info.IsTagged))
+	fmt.Fprintf(w, "Has structure tree: %s\n", boolToYesNo(info.HasStructTree))
+	if info.TaggingInconsistent {
+		fmt.Fprintln(w, "Warning: inconsistent accessibility markup (/MarkInfo /Marked and /StructTreeRoot disagree)")
+	}
+	printIfNotEmpty(w, "Declared language (/Lang)", info.DeclaredLanguage)
+	if info.LanguageMissing {
+		fmt.Fprintln(w, "Warning: tagged document without /Lang; screen readers cannot pick the right language (accessibility gap)")
+	}
+	printIfNotEmpty(w, "PDF/UA", info.PDFUAConformance)
+	if info.IsTagged || info.PDFUAConformance != "" {
+		if len(info.AccessibilityIssues) == 0 {
+			fmt.Fprintln(w, "Accessibility: no structural gaps found")
+		} else {
+			fmt.Fprintf(w, "Accessibility gaps: %s\n", strings.Join(info.AccessibilityIssues, "; "))
+		}
+	}
+	if len(info.RoleMap) > 0 {
+		roles := make([]string, 0, len(info.RoleMap))
+		for custom, standard := range info.RoleMap {
+			roles = append(roles, custom+" -> "+standard)
+		}
+		sort.Strings(roles)
+		fmt.Fprintf(w, "Structure role map: %s\n", strings.Join(roles, ", "))
+	}
+	if len(info.UnmappedStructureTypes) > 0 {
+		fmt.Fprintf(w, "Warning: unmapped custom structure types (not understood by assistive technology): %s\n",
+			strings.Join(info.UnmappedStructureTypes, ", "))
+	}
+	if total := info.TaggedContentOps + info.ArtifactContentOps + info.UntaggedContentOps + info.OrphanedContentOps; total > 0 {
+		fmt.Fprintf(w, "Content tagging: %d tagged, %d artifact, %d untagged, %d not in structure tree (of %d painting operations)\n",
+			info.TaggedContentOps, info.ArtifactContentOps, info.UntaggedContentOps, info.OrphanedContentOps, total)
+		if info.SubstantialUntaggedContent {
+			fmt.Fprintln(w, "Warning: substantial content is untagged although the document claims to be tagged")
+		}
+	}
+	fmt.Fprintf(w, "Has bookmarks: %s\n", boolToYesNo(info.HasBookmarks))
+	fmt.Fprintf(w, "Has attachments: %s\n", boolToYesNo(info.HasAttachments))
+	fmt.Fprintf(w, "Has forms: %s\n", boolToYesNo(info.HasForms))
+	fmt.Fprintf(w, "Has JavaScript: %s\n", boolToYesNo(info.HasJavaScript))
+	for _, snippet := range info.JavaScriptSnippets {
+		fmt.Fprintf(w, "  - %s\n", snippet)
+	}
+	fmt.Fprintf(w, "Has annotations: %s\n", boolToYesNo(info.HasAnnotations))
+	if info.HasAnnotations {
+		fmt.Fprintf(w, "Has markup annotations (notes, highlights, stamps): %s\n", boolToYesNo(info.HasMarkupAnnotations))
+	}
+	if info.WidgetAnnotationCount > 0 {
+		fmt.Fprintf(w, "Form field widgets: %d (not counted as annotations)\n", info.WidgetAnnotationCount)
+	}
+	fmt.Fprintf(w, "Has transparency: %s\n", transparencyText(info))
+	fmt.Fprintf(w, "Has layers: %s\n", boolToYesNo(info.HasLayers))
+	if len(info.HiddenLayers) > 0 {
+		fmt.Fprintf(w, "Warning: layers hidden by default (content still in the file): %s\n", strings.Join(info.HiddenLayers, ", "))
+	}
+	if info.AnnotationsWithoutAppearance > 0 {
+		fmt.Fprintf(w, "Annotations without appearance stream: %d (may not print or may look different across viewers)\n",
+			info.AnnotationsWithoutAppearance)
+	}
+	fmt.Fprintf(w, "Has digital signatures: %s\n", boolToYesNo(info.HasDigitalSignatures))
 	if info.HasDigitalSignatures {
-		fmt.Printf("Number of signatures: %d\n", info.SignatureCount)
+		fmt.Fprintf(w, "Number of signatures: %s\n", signatureCountText(info))
+	}
+	if len(info.FilterChains) > 0 {
+		chains := make([]string, 0, len(info.FilterChains))
+		for chain, count := range info.FilterChains {
+			chains = append(chains, fmt.Sprintf("%s (%d)", chain, count))
+		}
+		sort.Strings(chains)
+		fmt.Fprintf(w, "Stream filter chains: %s\n", strings.Join(chains, ", "))
+	}
+	if len(info.UnusualFilterChains) > 0 {
+		fmt.Fprintf(w, "Warning: unusual filter chains (possible obfuscation): %s\n", strings.Join(info.UnusualFilterChains, "; "))
+	}
+	if pa.ComputeEntropy && !info.FallbackParsing {
+		fmt.Fprintf(w, "High-entropy streams: %d\n", len(info.HighEntropyStreams))
+		if len(info.HighEntropyStreams) > 0 {
+			fmt.Fprintln(w, "Warning: streams with near-random content (possible hidden encrypted payload or archive)")
+		}
+		if len(info.HighestEntropyStreams) > 0 {
+			fmt.Fprintln(w, "Highest-entropy streams:")
+		}
+		for _, se := range info.HighestEntropyStreams {
+			fmt.Fprintf(w, "  - object %d (%s, %s): %.2f bits/byte\n", se.ObjectNumber, se.Kind,
+				formatFileSize(int64(se.Size)), se.Entropy)
+		}
+	}
+	if info.OptimizationSavings > 0 && info.FileSize > 0 {
+		fmt.Fprintf(w, "Optimization opportunity: ~%s (%.0f%% of the file)\n", formatFileSize(info.OptimizationSavings),
+			float64(info.OptimizationSavings)*100/float64(info.FileSize))
+		for _, finding := range info.OptimizationFindings {
+			fmt.Fprintf(w, "  - %s\n", finding)
+		}
+	}
+	if info.DanglingReferenceCount > 0 {
+		fmt.Fprintf(w, "Dangling references: %d (e.g. %s)\n", info.DanglingReferenceCount,
+			strings.Join(info.DanglingReferenceExamples, "; "))
 	}
 }
 
+// transparencyText answers whether the document uses transparency, naming the pages that do
+func transparencyText(info *PDFInfo) string {
+	if !info.HasTransparency {
+		return "No"
+	}
+	var pages []string
+	for _, page := range info.Pages {
+		if page.HasTransparency {
+			pages = append(pages, strconv.Itoa(page.Number))
+		}
+	}
+	if len(pages) == 0 {
+		return "Yes"
+	}
+	return fmt.Sprintf("Yes (pages %s)", strings.Join(pages, ", "))
+}
+
 // printSecurityInformation prints security and permissions information
-func (pa *PDFAnalyzer) printSecurityInformation(info *PDFInfo) {
-	fmt.Println("\n🔒 SECURITY INFORMATION")
-	fmt.Println(strings.Repeat("-", 50))
-	fmt.Printf("User password set: %s\n", boolToYesNo(info.UserPasswordSet))
-	fmt.Printf("Owner password set: %s\n", boolToYesNo(info.OwnerPasswordSet))
-	fmt.Printf("Printing allowed: %s\n", boolToYesNo(info.PrintAllowed))
-	fmt.Printf("Modification allowed: %s\n", boolToYesNo(info.ModifyAllowed))
-	fmt.Printf("Copy allowed: %s\n", boolToYesNo(info.CopyAllowed))
-	fmt.Printf("Add notes allowed: %s\n", boolToYesNo(info.AddNotesAllowed))
-	fmt.Printf("Fill forms allowed: %s\n", boolToYesNo(info.FillFormsAllowed))
-	fmt.Printf("Accessibility access: %s\n", boolToYesNo(info.AccessibilityAllowed))
-	fmt.Printf("Document assembly allowed: %s\n", boolToYesNo(info.AssembleAllowed))
-	fmt.Printf("High quality printing: %s\n", boolToYesNo(info.PrintHighQualityAllowed))
+func (pa *PDFAnalyzer) printSecurityInformation(w io.Writer, info *PDFInfo) {
+	fmt.Fprintln(w, "\n🔒 SECURITY INFORMATION")
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+	if info.SecurityHandler != "" {
+		fmt.Fprintf(w, "Security handler: %s (%s)\n", info.SecurityHandler, info.SecurityHandlerKind)
+	}
+	if info.IsRightsManaged {
+		fmt.Fprintln(w, "Note: protected by a rights-management service; opening it requires authorization from that service, not a password")
+	}
+	if !isStandardSecurityHandler(info) {
+		fmt.Fprintln(w, "Note: non-standard security handler; the permission flags below may not apply")
+	}
+	printIfNotEmpty(w, "Encryption", encryptionDescription(info))
+	if isStandardSecurityHandler(info) && !info.PermissionsEnforced {
+		fmt.Fprintln(w, "Note: permissions shown are advisory only for this encryption revision (they can be changed without the password)")
+	}
+	fmt.Fprintf(w, "User password set: %s\n", boolToYesNo(info.UserPasswordSet))
+	fmt.Fprintf(w, "Owner password set: %s\n", boolToYesNo(info.OwnerPasswordSet))
+	fmt.Fprintf(w, "Printing allowed: %s\n", boolToYesNo(info.PrintAllowed))
+	fmt.Fprintf(w, "Modification allowed: %s\n", boolToYesNo(info.ModifyAllowed))
+	fmt.Fprintf(w, "Copy allowed: %s\n", boolToYesNo(info.CopyAllowed))
+	fmt.Fprintf(w, "Add notes allowed: %s\n", boolToYesNo(info.AddNotesAllowed))
+	fmt.Fprintf(w, "Fill forms allowed: %s\n", boolToYesNo(info.FillFormsAllowed))
+	fmt.Fprintf(w, "Accessibility access: %s\n", boolToYesNo(info.AccessibilityAllowed))
+	fmt.Fprintf(w, "Document assembly allowed: %s\n", boolToYesNo(info.AssembleAllowed))
+	fmt.Fprintf(w, "High quality printing: %s\n", boolToYesNo(info.PrintHighQualityAllowed))
+}
+
+// printSecurityWarnings prints the deprecated or weak protection found in the document
+func (pa *PDFAnalyzer) printSecurityWarnings(w io.Writer, info *PDFInfo) {
+	fmt.Fprintln(w, "\n⚠️ SECURITY WARNINGS")
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+	for _, warning := range info.SecurityWarnings {
+		fmt.Fprintf(w, "- %s\n", warning)
+	}
+}
+
+// signatureCountText renders the number of signatures, marked when it is only an estimate
+func signatureCountText(info *PDFInfo) string {
+	if info.SignatureCountEstimated {
+		return fmt.Sprintf("%d (estimated)", info.SignatureCount)
+	}
+	return fmt.Sprintf("%d", info.SignatureCount)
+}
+
+// printSuspiciousActions prints the actions that launch programs, open URLs or remote files, or
+// submit data, with where they are triggered
+func (pa *PDFAnalyzer) printSuspiciousActions(w io.Writer, info *PDFInfo) {
+	fmt.Fprintln(w, "\n⚠️ SECURITY: EXTERNAL ACTIONS")
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+	for _, a := range info.SuspiciousActions {
+		target := a.Target
+		if target == "" {
+			target = "(no target)"
+		}
+		fmt.Fprintf(w, "- %s (%s): %s\n", a.Type, a.Location, target)
+	}
+}
+
+// printRiskAssessment prints the malware triage score and the factors behind it
+func (pa *PDFAnalyzer) printRiskAssessment(w io.Writer, info *PDFInfo) {
+	fmt.Fprintln(w, "\n🚨 RISK ASSESSMENT")
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+	fmt.Fprintf(w, "Risk score: %d/100 (%s)\n", info.RiskScore, riskLevel(info.RiskScore))
+	for _, factor := range info.RiskFactors {
+		fmt.Fprintf(w, "- %s\n", factor)
+	}
+}
+
+// printFormInformation prints AcroForm information
+func (pa *PDFAnalyzer) printFormInformation(w io.Writer, info *PDFInfo) {
+	fmt.Fprintln(w, "\n📋 FORM INFORMATION")
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+	if info.HasXFA {
+		fmt.Fprintln(w, "Form type: XFA")
+		printIfNotEmpty(w, "XFA template", info.XFATemplateName)
+		fmt.Fprintf(w, "XFA packet length: %d bytes\n", info.XFAPacketLength)
+		if len(info.FormFields) == 0 {
+			fmt.Fprintln(w, "Note: the fields are defined only in the XFA packets, so no AcroForm fields are listed")
+		}
+	} else {
+		fmt.Fprintln(w, "Form type: AcroForm")
+	}
+	if total := info.FilledFieldCount + info.EmptyFieldCount; total > 0 {
+		fmt.Fprintf(w, "Fields filled: %d of %d\n", info.FilledFieldCount, total)
+	}
+	if info.FormDefaultAppearance != "" {
+		fmt.Fprintf(w, "Default appearance (DA): %s\n", info.FormDefaultAppearance)
+	} else {
+		fmt.Fprintln(w, "Default appearance (DA): not set")
+	}
+	if len(info.FormDefaultFonts) > 0 {
+		fmt.Fprintf(w, "Default resource fonts (DR): %s\n", strings.Join(info.FormDefaultFonts, ", "))
+	} else {
+		fmt.Fprintln(w, "Default resource fonts (DR): none")
+	}
+	if info.FormDefaultFontMissing {
+		fmt.Fprintf(w, "Warning: font /%s used by the default appearance is not defined in the default resources\n",
+			defaultAppearanceFont(info.FormDefaultAppearance))
+	}
+}
+
+// printFormFields lists the form fields with their type, flags and current value
+func (pa *PDFAnalyzer) printFormFields(w io.Writer, info *PDFInfo) {
+	fmt.Fprintln(w, "\n🧾 FORM FIELDS")
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+	for _, field := range info.FormFields {
+		attrs := []string{fieldTypeName(field.Type)}
+		if field.Required {
+			attrs = append(attrs, "required")
+		}
+		if field.ReadOnly {
+			attrs = append(attrs, "read-only")
+		}
+		value := field.Value
+		if value == "" {
+			value = "(empty)"
+		}
+		name := field.Name
+		if name == "" {
+			name = "(unnamed)"
+		}
+		fmt.Fprintf(w, "- %s (%s): %s\n", name, strings.Join(attrs, ", "), value)
+	}
 }
 
 // printContentInformation prints content analysis information
-func (pa *PDFAnalyzer) printContentInformation(info *PDFInfo) {
-	fmt.Println("\n📝 CONTENT INFORMATION")
-	fmt.Println(strings.Repeat("-", 50))
-	fmt.Printf("Total text characters: %d\n", info.TotalTextLength)
-	fmt.Printf("Number of images: %d\n", info.ImagesCount)
+func (pa *PDFAnalyzer) printContentInformation(w io.Writer, info *PDFInfo) {
+	fmt.Fprintln(w, "\n📝 CONTENT INFORMATION")
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+	printIfNotEmpty(w, "Document class", info.DocumentClass)
+	fmt.Fprintf(w, "Total text characters: %d\n", info.TotalTextLength)
+	fmt.Fprintf(w, "Total words: %d\n", info.TotalWordCount)
+	fmt.Fprintf(w, "Total lines: %d\n", info.TotalLineCount)
+	if info.DetectedLanguage != "" {
+		fmt.Fprintf(w, "Detected language: %s, confidence %.0f%%\n", languageLabel(info.DetectedLanguage), info.LanguageConfidence*100)
+	}
+	if info.LanguageMismatch {
+		fmt.Fprintln(w, "Warning: the declared language differs from the language detected in the text")
+	}
+	if pa.CrossCheckText && !info.FallbackParsing {
+		fmt.Fprintf(w, "Text characters in content streams: %d\n", info.ContentStreamTextLength)
+		if info.TextExtractionDiscrepancy {
+			fmt.Fprintln(w, "Warning: text extraction is parser-dependent for this file")
+		}
+	}
+	fmt.Fprintf(w, "Number of images: %d\n", info.ImagesCount)
+	if info.PageCount > 0 {
+		fmt.Fprintf(w, "Likely scanned (no text layer): %s\n", boolToYesNo(info.IsProbablyScanned))
+	}
+	if len(info.Pages) > 0 {
+		fmt.Fprintf(w, "Print color: %d color pages, %d B&W pages (color coverage: %s)\n",
+			info.ColorPageCount, info.BlackWhitePageCount,
+			colorCoverageLabel(info.ColorPageCount, len(info.Pages)))
+	}
+	if len(info.ColorSpaces) > 0 {
+		fmt.Fprintf(w, "Color spaces: %s\n", strings.Join(info.ColorSpaces, ", "))
+	}
+	if len(info.SpotColors) > 0 {
+		fmt.Fprintf(w, "Spot colors: %s\n", strings.Join(info.SpotColors, ", "))
+	}
 	if len(info.FontsUsed) > 0 {
-		fmt.Printf("Fonts used: %s\n", strings.Join(info.FontsUsed, ", "))
+		names := make([]string, 0, len(info.FontsUsed))
+		var missing []string
+		for _, font := range info.FontsUsed {
+			names = append(names, font.Name)
+			if !font.Embedded {
+				missing = append(missing, font.Name)
+			}
+		}
+		fmt.Fprintf(w, "Fonts used: %s\n", strings.Join(names, ", "))
+		fmt.Fprintf(w, "All fonts embedded: %s\n", boolToYesNo(info.AllFontsEmbedded))
+		if len(missing) > 0 {
+			fmt.Fprintf(w, "Warning: fonts not embedded (the text may look different on machines without them): %s\n",
+				strings.Join(missing, ", "))
+		}
 	}
 }
 
 // printPageInformation prints information about PDF pages
-func (pa *PDFAnalyzer) printPageInformation(info *PDFInfo) {
-	fmt.Println("\n📖 PAGE INFORMATION")
-	fmt.Println(strings.Repeat("-", 50))
-	for i, page := range info.Pages {
-		if i < 5 { // Show only the first 5 pages
-			fmt.Printf("Page %d: %.1f x %.1f pts, rotation: %d°, text: %d chars\n",
-				page.Number, page.Width, page.Height, page.Rotation, page.TextLength)
+func (pa *PDFAnalyzer) printPageInformation(w io.Writer, info *PDFInfo) {
+	fmt.Fprintln(w, "\n📖 PAGE INFORMATION")
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+	shown := pa.PageRange.pagesToShow(info.Pages)
+	if pa.PageRange.First > 0 && len(shown) == 0 {
+		fmt.Fprintf(w, "No pages in range %s (the document has %d pages)\n", pa.PageRange.String(), len(info.Pages))
+		return
+	}
+	for _, page := range shown {
+		size := ""
+		if page.PaperSize != "" {
+			size = " (" + page.PaperSize + ")"
+		}
+		fmt.Fprintf(w, "Page %d: %.1f x %.1f pts%s, rotation: %d°, text: %d chars, %d words, %d lines, images: %d\n",
+			page.Number, page.EffectiveWidth, page.EffectiveHeight, size, page.Rotation, page.TextLength, page.WordCount, page.LineCount, page.ImageCount)
+		// O tamanho mostrado é o da CropBox; a MediaBox fica como referência
+		if page.CropBox != nil && page.MediaBox != nil && !page.CropBox.Equal(*page.MediaBox) {
+			fmt.Fprintf(w, "  MediaBox: %s, CropBox: %s\n", page.MediaBox, page.CropBox)
+		}
+		if page.TrimBox != nil {
+			fmt.Fprintf(w, "  TrimBox: %s\n", page.TrimBox)
+		}
+		if len(page.ColorSpaces) > 0 {
+			fmt.Fprintf(w, "  Color spaces: %s\n", strings.Join(page.ColorSpaces, ", "))
+		}
+	}
+	// Sem intervalo pedido, apenas as primeiras páginas são listadas
+	if pa.PageRange.First == 0 && len(info.Pages) > len(shown) {
+		fmt.Fprintf(w, "... and %d more pages (use --all-pages to list them)\n", len(info.Pages)-len(shown))
+	}
+	// Problemas de limites valem para todas as páginas, mesmo as não listadas
+	for _, page := range info.Pages {
+		for _, issue := range page.BoxIssues {
+			fmt.Fprintf(w, "Warning: page %d: %s\n", page.Number, issue)
+		}
+	}
+}
+
+// printAnnotations lists the annotations grouped by type, with counts
+func (pa *PDFAnalyzer) printAnnotations(w io.Writer, info *PDFInfo) {
+	fmt.Fprintln(w, "\n📝 ANNOTATIONS")
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+	fmt.Fprintf(w, "Total annotations: %d\n", len(info.Annotations))
+	for _, group := range annotationsByType(info.Annotations) {
+		fmt.Fprintf(w, "%s: %d\n", group[0].Type, len(group))
+		for i, annot := range group {
+			if i == maxAnnotationsListed {
+				fmt.Fprintf(w, "  ... and %d more\n", len(group)-maxAnnotationsListed)
+				break
+			}
+			if annot.Content != "" {
+				fmt.Fprintf(w, "  Page %d: %q\n", annot.Page, shortenText(annot.Content, maxAnnotationContent))
+			} else {
+				fmt.Fprintf(w, "  Page %d\n", annot.Page)
+			}
 		}
 	}
-	if len(info.Pages) > 5 {
-		fmt.Printf("... and %d more pages\n", len(info.Pages)-5)
+}
+
+// printLayers lists the optional content groups and whether each is visible when the document opens
+func (pa *PDFAnalyzer) printLayers(w io.Writer, info *PDFInfo) {
+	fmt.Fprintln(w, "\n🗂️  LAYERS")
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+	for _, layer := range info.Layers {
+		visibility := "visible by default"
+		if !layer.Visible {
+			visibility = "hidden by default"
+		}
+		fmt.Fprintf(w, "  %s: %s\n", layer.Name, visibility)
 	}
 }
 
 // printBookmarks prints bookmark information
-func (pa *PDFAnalyzer) printBookmarks(info *PDFInfo) {
-	fmt.Println("\n🔖 BOOKMARKS")
-	fmt.Println(strings.Repeat("-", 50))
+func (pa *PDFAnalyzer) printBookmarks(w io.Writer, info *PDFInfo) {
+	fmt.Fprintln(w, "\n🔖 BOOKMARKS")
+	fmt.Fprintln(w, strings.Repeat("-", 50))
 	for _, bookmark := range info.Bookmarks {
 		indent := strings.Repeat("  ", bookmark.Level-1)
-		fmt.Printf("%s- %s (page %d)\n", indent, bookmark.Title, bookmark.Page)
+		fmt.Fprintf(w, "%s- %s (page %d)\n", indent, bookmark.Title, bookmark.Page)
 	}
 }
 
 // printAttachments prints attachment information
-func (pa *PDFAnalyzer) printAttachments(info *PDFInfo) {
-	fmt.Println("\n📎 ATTACHMENTS")
-	fmt.Println(strings.Repeat("-", 50))
+func (pa *PDFAnalyzer) printAttachments(w io.Writer, info *PDFInfo) {
+	fmt.Fprintln(w, "\n📎 ATTACHMENTS")
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+	var dangerous []string
 	for _, attachment := range info.Attachments {
-		fmt.Printf("- %s (%s, %s)\n", attachment.Name, attachment.Type, formatFileSize(attachment.Size))
+		category := ""
+		if attachment.Category != "" {
+			category = " [" + attachment.Category + "]"
+		}
+		fmt.Fprintf(w, "- %s (%s, %s)%s\n", attachment.Name, attachment.Type, formatFileSize(attachment.Size), category)
+		if attachment.Dangerous {
+			dangerous = append(dangerous, attachment.Name)
+		}
+	}
+	if len(dangerous) > 0 {
+		fmt.Fprintf(w, "Warning: attachments that run code when opened (possible malware): %s\n", strings.Join(dangerous, ", "))
+	}
+	if pa.ExtractAttachmentsDir != "" {
+		fmt.Fprintf(w, "Files written to %s: %d of %d\n", pa.ExtractAttachmentsDir, info.AttachmentsExtracted, len(info.Attachments))
+	}
+}
+
+// printEmbeddedPDFs lists the embedded PDFs; their full reports follow the parent's
+func (pa *PDFAnalyzer) printEmbeddedPDFs(w io.Writer, info *PDFInfo) {
+	fmt.Fprintln(w, "\n📦 EMBEDDED PDFS")
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+	for _, embedded := range info.EmbeddedPDFs {
+		if embedded.Info == nil {
+			fmt.Fprintf(w, "- %s (not analyzed: %s)\n", embedded.Name, embedded.SkipReason)
+			continue
+		}
+		fmt.Fprintf(w, "- %s (%d pages, PDF %s; full report below)\n",
+			embedded.Name, embedded.Info.PageCount, embedded.Info.PDFVersion)
 	}
 }
 
 // printDigitalSignatures prints digital signature information
-func (pa *PDFAnalyzer) printDigitalSignatures(info *PDFInfo) {
-	fmt.Println("\n🔐 DIGITAL SIGNATURES")
-	fmt.Println(strings.Repeat("-", 50))
-	fmt.Printf("Document has signatures: %s\n", boolToYesNo(info.HasDigitalSignatures))
-	fmt.Printf("Number of signatures: %d\n", info.SignatureCount)
+func (pa *PDFAnalyzer) printDigitalSignatures(w io.Writer, info *PDFInfo) {
+	fmt.Fprintln(w, "\n🔐 DIGITAL SIGNATURES")
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+	fmt.Fprintf(w, "Document has signatures: %s\n", boolToYesNo(info.HasDigitalSignatures))
+	fmt.Fprintf(w, "Number of signatures: %s\n", signatureCountText(info))
+	if info.DocumentTimestampCount > 0 {
+		fmt.Fprintf(w, "Number of document timestamps: %d\n", info.DocumentTimestampCount)
+	}
+	if len(info.Signatures) > 1 {
+		if info.SignatureOrderConsistent {
+			fmt.Fprintln(w, "Signature order: Consistent")
+		} else {
+			fmt.Fprintln(w, "Signature order: Inconsistent")
+		}
+	}
+	for _, issue := range info.SignatureOrderIssues {
+		fmt.Fprintf(w, "Warning: %s\n", issue)
+	}
 
 	if info.HasDigitalSignatures && len(info.Signatures) > 0 {
-		fmt.Println("\nSignature details:")
-		for i, sig := range info.Signatures {
-			fmt.Printf("\n  Signature %d:\n", i+1)
+		if info.SignatureCount > 0 {
+			fmt.Fprintln(w, "\nSignature details:")
+		}
+		number := 0
+		for _, sig := range info.Signatures {
+			if sig.IsDocumentTimestamp {
+				continue
+			}
+			number++
+			fmt.Fprintf(w, "\n  Signature %d of %d%s:\n", number, info.SignatureCount, appliedInRevision(sig))
 			if sig.FieldName != "" {
-				fmt.Printf("    Field: %s\n", sig.FieldName)
+				fmt.Fprintf(w, "    Field: %s\n", sig.FieldName)
 			}
-			fmt.Printf("    Type: %s\n", sig.Type)
+			fmt.Fprintf(w, "    Type: %s\n", sig.Type)
 			if sig.SubFilter != "" {
-				fmt.Printf("    SubFilter: %s\n", sig.SubFilter)
+				fmt.Fprintf(w, "    SubFilter: %s\n", sig.SubFilter)
 			}
-			fmt.Printf("    Status: %s\n", sig.Status)
-			fmt.Printf("    Valid: %s\n", boolToYesNo(sig.IsValid))
-			fmt.Printf("    Certified: %s\n", boolToYesNo(sig.IsCertified))
+			fmt.Fprintf(w, "    Status: %s\n", sig.Status)
+			fmt.Fprintf(w, "    Valid: %s\n", boolToYesNo(sig.IsValid))
+			fmt.Fprintf(w, "    Certified: %s\n", boolToYesNo(sig.IsCertified))
+			printModifiedAfterSigning(w, sig, "signing")
 			if sig.SignerName != "" {
-				fmt.Printf("    Signer: %s\n", sig.SignerName)
+				fmt.Fprintf(w, "    Signer: %s\n", sig.SignerName)
+			}
+			if sig.SignerCN != "" {
+				fmt.Fprintf(w, "    Certificate subject: %s\n", sig.SignerCN)
+				if sig.SignerOrg != "" {
+					fmt.Fprintf(w, "    Organization: %s\n", sig.SignerOrg)
+				}
+				if sig.SignerCPF != "" {
+					fmt.Fprintf(w, "    CPF: %s\n", sig.SignerCPF)
+				}
+				if sig.SignerCNPJ != "" {
+					fmt.Fprintf(w, "    CNPJ: %s\n", sig.SignerCNPJ)
+				}
+				fmt.Fprintf(w, "    Certificate issuer: %s\n", sig.IssuerCN)
+				fmt.Fprintf(w, "    Certificate serial: %s\n", sig.CertSerialNumber)
+				fmt.Fprintf(w, "    Certificate validity: %s to %s\n", sig.CertNotBefore, sig.CertNotAfter)
+				if sig.ChainTrusted {
+					fmt.Fprintln(w, "    Certificate chain: trusted")
+				} else {
+					fmt.Fprintf(w, "    Certificate chain: not trusted (%s)\n", sig.ChainError)
+				}
 			}
 			if sig.SigningTime != "" {
-				fmt.Printf("    Signing date/time: %s\n", sig.SigningTime)
+				fmt.Fprintf(w, "    Signing date/time: %s\n", sig.SigningTime)
 			}
 			if sig.Location != "" {
-				fmt.Printf("    Location: %s\n", sig.Location)
+				fmt.Fprintf(w, "    Location: %s\n", sig.Location)
 			}
 			if sig.Reason != "" {
-				fmt.Printf("    Reason: %s\n", sig.Reason)
+				fmt.Fprintf(w, "    Reason: %s\n", sig.Reason)
 			}
 			if sig.ContactInfo != "" {
-				fmt.Printf("    Contact: %s\n", sig.ContactInfo)
+				fmt.Fprintf(w, "    Contact: %s\n", sig.ContactInfo)
 			}
 			
 			// Timestamp information
-			fmt.Printf("    Has timestamp: %s\n", boolToYesNo(sig.HasTimestamp))
+			fmt.Fprintf(w, "    Has timestamp: %s\n", boolToYesNo(sig.HasTimestamp))
 			if sig.HasTimestamp {
 				if sig.TimestampType != "" {
-					fmt.Printf("    Timestamp type: %s\n", sig.TimestampType)
+					fmt.Fprintf(w, "    Timestamp type: %s\n", sig.TimestampType)
 				}
 				if sig.TimestampTime != "" {
-					fmt.Printf("    Timestamp time: %s\n", sig.TimestampTime)
+					fmt.Fprintf(w, "    Timestamp time: %s\n", sig.TimestampTime)
 				}
 				if sig.TimestampAuthority != "" {
-					fmt.Printf("    Timestamp authority: %s\n", sig.TimestampAuthority)
+					fmt.Fprintf(w, "    Timestamp authority: %s\n", sig.TimestampAuthority)
 				}
 				if sig.TimestampStatus != "" {
-					fmt.Printf("    Timestamp status: %s\n", sig.TimestampStatus)
+					fmt.Fprintf(w, "    Timestamp status: %s\n", sig.TimestampStatus)
 				}
 			}
+
+			if sig.SignedBeforeTrustedTime != "" {
+				deadline := pa.TrustedTime.Format(time.RFC3339)
+				if sig.TrustedTimeSource != "" {
+					fmt.Fprintf(w, "    Signed before deadline (%s): %s (per %s)\n", deadline, sig.SignedBeforeTrustedTime, sig.TrustedTimeSource)
+				} else {
+					fmt.Fprintf(w, "    Signed before deadline (%s): %s\n", deadline, sig.SignedBeforeTrustedTime)
+				}
+			}
+			if sig.AppearancePage > 0 {
+				fmt.Fprintf(w, "    Appearance: page %d\n", sig.AppearancePage)
+			}
+			if sig.AppearanceOverlapsText {
+				fmt.Fprintf(w, "    Warning: signature %d appearance overlaps document text on page %d (%q)\n",
+					number, sig.AppearancePage, sig.OverlappedText)
+			}
+			printByteRangeCoverage(w, sig)
 			
 			if len(sig.ValidationErrors) > 0 {
-				fmt.Printf("    Validation issues:\n")
+				fmt.Fprintf(w, "    Validation issues:\n")
 				for _, err := range sig.ValidationErrors {
-					fmt.Printf("      - %s\n", err)
+					fmt.Fprintf(w, "      - %s\n", err)
 				}
 			}
 		}
+
+		if info.DocumentTimestampCount > 0 {
+			pa.printDocumentTimestamps(w, info)
+		}
 	} else if info.HasDigitalSignatures {
-		fmt.Println("\nDigital signature(s) detected in document.")
-		fmt.Printf("Found %d signature(s), but detailed validation failed due to encryption or security restrictions.\n", info.SignatureCount)
-		fmt.Println("\nSignature validation requires:")
-		fmt.Println("  • Document decryption (if encrypted)")
-		fmt.Println("  • Access to signing certificates")
-		fmt.Println("  • Valid certificate chain")
-		fmt.Println("  • Trusted certificate authority (CA)")
+		fmt.Fprintln(w, "\nDigital signature(s) detected in document.")
+		fmt.Fprintf(w, "Found %d signature(s), but detailed validation failed due to encryption or security restrictions.\n", info.SignatureCount)
+		fmt.Fprintln(w, "\nSignature validation requires:")
+		fmt.Fprintln(w, "  • Document decryption (if encrypted)")
+		fmt.Fprintln(w, "  • Access to signing certificates")
+		fmt.Fprintln(w, "  • Valid certificate chain")
+		fmt.Fprintln(w, "  • Trusted certificate authority (CA)")
 	} else {
-		fmt.Println("\nThis document does not have digital signatures.")
-		fmt.Println("To digitally sign a PDF, you can use:")
-		fmt.Println("  • Adobe Acrobat")
-		fmt.Println("  • LibreOffice")
-		fmt.Println("  • Online signature tools")
-		fmt.Println("  • ICP-Brasil digital certificates")
+		fmt.Fprintln(w, "\nThis document does not have digital signatures.")
+		fmt.Fprintln(w, "To digitally sign a PDF, you can use:")
+		fmt.Fprintln(w, "  • Adobe Acrobat")
+		fmt.Fprintln(w, "  • LibreOffice")
+		fmt.Fprintln(w, "  • Online signature tools")
+		fmt.Fprintln(w, "  • ICP-Brasil digital certificates")
+	}
+}
+
+// printModifiedAfterSigning prints whether incremental updates were appended after the signed
+// byte range, and what they changed. The event is "signing" or "timestamp".
+func printModifiedAfterSigning(w io.Writer, sig DigitalSignatureInfo, event string) {
+	if sig.SignedRangeEnd == 0 {
+		return
+	}
+	if !sig.ModifiedAfterSigning {
+		fmt.Fprintf(w, "    Modified after %s: No\n", event)
+		return
+	}
+	fmt.Fprintf(w, "    ⚠️  Modified after %s: Yes, %d incremental %s appended afterward\n",
+		event, sig.IncrementalUpdatesAfterSig, pluralize("update", sig.IncrementalUpdatesAfterSig))
+	if sig.ModificationsAfterSigning > 0 {
+		fmt.Fprintf(w, "    Changes: %d objects (%s)\n", sig.ModificationsAfterSigning, sig.ModificationSummary)
+	}
+	if len(sig.ObjectsModifiedAfter) > 0 {
+		objNrs := make([]string, len(sig.ObjectsModifiedAfter))
+		for j, objNr := range sig.ObjectsModifiedAfter {
+			objNrs[j] = fmt.Sprintf("%d", objNr)
+		}
+		fmt.Fprintf(w, "    Objects changed: %s\n", strings.Join(objNrs, ", "))
+	}
+}
+
+// printByteRangeCoverage prints what the signed byte range covers and whether its digest matches
+func printByteRangeCoverage(w io.Writer, sig DigitalSignatureInfo) {
+	if sig.SignedRangeEnd == 0 {
+		return
+	}
+	fmt.Fprintf(w, "    Covers whole document: %s\n", boolToYesNo(sig.CoversWholeDocument))
+	if sig.BytesAfterSignature > 0 {
+		fmt.Fprintf(w, "    Warning: %d bytes after the signed byte range are not covered by this signature\n", sig.BytesAfterSignature)
+	}
+	switch sig.ByteRangeDigest {
+	case digestMatch:
+		fmt.Fprintln(w, "    Signed bytes digest: matches the signature")
+	case digestMismatch:
+		fmt.Fprintln(w, "    Warning: the digest of the signed bytes does not match the signature")
+	}
+}
+
+// printDocumentHistory lists the revisions of the document and the signature that sealed each one
+func (pa *PDFAnalyzer) printDocumentHistory(w io.Writer, info *PDFInfo) {
+	if len(info.Revisions) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\n📜 DOCUMENT HISTORY")
+	fmt.Fprintln(w, strings.Repeat("-", 50))
+	for _, rev := range info.Revisions {
+		fmt.Fprintf(w, "Revision %d of %d: offset %d, %d bytes, PDF %s", rev.Number, len(info.Revisions), rev.Offset, rev.Size, rev.Version)
+		if rev.SignedBy != "" {
+			fmt.Fprintf(w, ", signed by %s", rev.SignedBy)
+			if rev.SignatureField != "" {
+				fmt.Fprintf(w, " (field %s)", rev.SignatureField)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// appliedInRevision describes the revision a signature was applied in, e.g. ", applied in revision 4"
+func appliedInRevision(sig DigitalSignatureInfo) string {
+	if sig.Revision == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", applied in revision %d", sig.Revision)
+}
+
+// printDocumentTimestamps prints the document timestamps (DTS) found among the signatures
+func (pa *PDFAnalyzer) printDocumentTimestamps(w io.Writer, info *PDFInfo) {
+	fmt.Fprintln(w, "\nDocument Timestamps:")
+	number := 0
+	for _, sig := range info.Signatures {
+		if !sig.IsDocumentTimestamp {
+			continue
+		}
+		number++
+		fmt.Fprintf(w, "\n  Timestamp %d of %d%s:\n", number, info.DocumentTimestampCount, appliedInRevision(sig))
+		if sig.FieldName != "" {
+			fmt.Fprintf(w, "    Field: %s\n", sig.FieldName)
+		}
+		fmt.Fprintf(w, "    Status: %s\n", sig.Status)
+		fmt.Fprintf(w, "    Valid: %s\n", boolToYesNo(sig.IsValid))
+		printModifiedAfterSigning(w, sig, "timestamp")
+		if sig.TimestampTime != "" {
+			fmt.Fprintf(w, "    Timestamp time: %s\n", sig.TimestampTime)
+		}
+		if sig.TimestampAuthority != "" {
+			fmt.Fprintf(w, "    Timestamp authority: %s\n", sig.TimestampAuthority)
+		}
+		if sig.SignedBeforeTrustedTime != "" {
+			fmt.Fprintf(w, "    Before deadline (%s): %s\n", pa.TrustedTime.Format(time.RFC3339), sig.SignedBeforeTrustedTime)
+		}
+		printByteRangeCoverage(w, sig)
+		if len(sig.ValidationErrors) > 0 {
+			fmt.Fprintf(w, "    Validation issues:\n")
+			for _, err := range sig.ValidationErrors {
+				fmt.Fprintf(w, "      - %s\n", err)
+			}
+		}
 	}
 }
 
 // printReportFooter prints the report footer
-func (pa *PDFAnalyzer) printReportFooter() {
-	fmt.Println("\n" + strings.Repeat("=", 80))
-	fmt.Printf("Analysis completed at: %s\n", time.Now().Format("2006-01-02 15:04:05"))
-	fmt.Println(strings.Repeat("=", 80))
+func (pa *PDFAnalyzer) printReportFooter(w io.Writer) {
+	fmt.Fprintln(w, "\n" + strings.Repeat("=", 80))
+	fmt.Fprintf(w, "Analysis completed at: %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Fprintln(w, strings.Repeat("=", 80))
 }
@@ -0,0 +1,450 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+var (
+	// xrefSectionPattern matches the start of a classic cross-reference section (not "startxref")
+	xrefSectionPattern = regexp.MustCompile(`(?:^|[\r\n])xref\s`)
+
+	catalogTypePattern    = regexp.MustCompile(`/Type\s*/Catalog\b`)
+	catalogVersionPattern = regexp.MustCompile(`/Version\s*/(\d\.\d)`)
+)
+
+// revisionEnds returns the offset just past each %%EOF marker, i.e. the end of each revision
+// written by incremental updates. The first-page section of a linearized file is not a revision.
+func revisionEnds(data []byte) []int {
+	var ends []int
+	marker := []byte("%%EOF")
+	for offset := 0; ; {
+		idx := bytes.Index(data[offset:], marker)
+		if idx < 0 {
+			break
+		}
+		offset += idx + len(marker)
+		ends = append(ends, offset)
+	}
+	if len(ends) > 1 && bytes.Contains(data[:ends[0]], []byte("/Linearized")) {
+		ends = ends[1:]
+	}
+	return ends
+}
+
+// analyzeRevisionVersions reports the PDF version in effect after each revision: the header
+// version first, then any catalog /Version written by a later incremental update
+func (pa *PDFAnalyzer) analyzeRevisionVersions(data []byte, info *PDFInfo) {
+	header := fallbackVersionPattern.FindSubmatch(data)
+	if header == nil {
+		return
+	}
+	ends := revisionEnds(data)
+	info.RevisionCount = len(ends)
+
+	current := string(header[1])
+	start := 0
+	info.Revisions = make([]RevisionInfo, 0, len(ends))
+	for i, end := range ends {
+		for _, body := range fallbackObjects(data[start:end]) {
+			if !catalogTypePattern.MatchString(body) {
+				continue
+			}
+			if m := catalogVersionPattern.FindStringSubmatch(body); m != nil && compareVersions(m[1], current) > 0 {
+				current = m[1]
+			}
+		}
+		info.RevisionVersions = append(info.RevisionVersions, current)
+		info.Revisions = append(info.Revisions, RevisionInfo{
+			Number:  i + 1,
+			Offset:  int64(start),
+			Size:    int64(end - start),
+			Version: current,
+		})
+		start = end
+	}
+}
+
+// versionHistory explains version upgrades across revisions, e.g.
+// "created as 1.4, upgraded to 1.7 in revision 2"; it returns "" when the version never changed
+func versionHistory(versions []string) string {
+	if len(versions) < 2 {
+		return ""
+	}
+	parts := []string{"created as " + versions[0]}
+	for i := 1; i < len(versions); i++ {
+		if versions[i] != versions[i-1] {
+			parts = append(parts, fmt.Sprintf("upgraded to %s in revision %d", versions[i], i+1))
+		}
+	}
+	if len(parts) == 1 {
+		return ""
+	}
+	return strings.Join(parts, ", ")
+}
+
+// signedRange is the byte range covered by one signature
+type signedRange struct {
+	FieldName string
+	ByteRange []int64 // [offset1 length1 offset2 length2]
+	Contents  []byte  // the signature's /Contents (CMS or timestamp token), zero-padded
+}
+
+// End returns the offset right after the last signed byte, i.e. the file size at signing time
+func (sr signedRange) End() int64 {
+	return sr.ByteRange[2] + sr.ByteRange[3]
+}
+
+// signatureByteRanges collects the /ByteRange of every signature field, ordered by where the
+// signed range ends in the file
+func (pa *PDFAnalyzer) signatureByteRanges(ctx *model.Context) []signedRange {
+	var ranges []signedRange
+	seen := make(map[string]bool)
+
+	pa.forEachDict(ctx, func(objNr int, d types.Dict) {
+		if ft := d.NameEntry("FT"); ft == nil || *ft != "Sig" {
+			return
+		}
+		vObj, found := d.Find("V")
+		if !found || vObj == nil {
+			return
+		}
+		sigDict, err := ctx.DereferenceDict(vObj)
+		if err != nil || sigDict == nil {
+			return
+		}
+		brObj, found := sigDict.Find("ByteRange")
+		if !found {
+			return
+		}
+		arr, err := ctx.DereferenceArray(brObj)
+		if err != nil || len(arr) != 4 {
+			return
+		}
+		byteRange := make([]int64, 4)
+		for i, o := range arr {
+			n, err := ctx.DereferenceInteger(o)
+			if err != nil || n == nil {
+				return
+			}
+			byteRange[i] = int64(*n)
+		}
+
+		name := getStringFromDict(d, "T")
+		key := fmt.Sprintf("%s/%v", name, byteRange)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		sr := signedRange{FieldName: name, ByteRange: byteRange}
+		if obj, found := sigDict.Find("Contents"); found {
+			if hex, ok := obj.(types.HexLiteral); ok {
+				sr.Contents, _ = hex.Bytes()
+			}
+		}
+		ranges = append(ranges, sr)
+	})
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].End() < ranges[j].End() })
+	return ranges
+}
+
+// signatureOrderIssues checks that the signatures were applied one after the other: every
+// signed range starts at the beginning of the file, and each later signature covers the whole
+// file as it was when the previous one was applied. The ranges must be ordered by End.
+func signatureOrderIssues(ranges []signedRange) []string {
+	var issues []string
+	label := func(i int) string {
+		if ranges[i].FieldName != "" {
+			return fmt.Sprintf("%q", ranges[i].FieldName)
+		}
+		return fmt.Sprintf("#%d", i+1)
+	}
+
+	for i, sr := range ranges {
+		if sr.ByteRange[0] != 0 {
+			issues = append(issues, fmt.Sprintf("signature %s does not cover the start of the file", label(i)))
+		}
+		if i == 0 {
+			continue
+		}
+		// Os bytes assinados vão até o início de /Contents desta assinatura
+		if coveredUntil := sr.ByteRange[0] + sr.ByteRange[1]; coveredUntil < ranges[i-1].End() {
+			issues = append(issues, fmt.Sprintf("signature %s does not cover earlier signature %s", label(i), label(i-1)))
+		}
+	}
+	return issues
+}
+
+// findSignedRange returns the byte range of the signature with the given field name.
+// Field names reported by validation may be fully qualified ("parent.child").
+func findSignedRange(ranges []signedRange, fieldName string) (signedRange, bool) {
+	for _, sr := range ranges {
+		if sr.FieldName != "" && (sr.FieldName == fieldName || strings.HasSuffix(fieldName, "."+sr.FieldName)) {
+			return sr, true
+		}
+	}
+	if len(ranges) == 1 {
+		return ranges[0], true
+	}
+	return signedRange{}, false
+}
+
+// objectOffset returns the byte offset where the current definition of an object lives.
+// Objects stored in an object stream take the offset of that stream.
+func objectOffset(ctx *model.Context, entry *model.XRefTableEntry) (int64, bool) {
+	if entry.Compressed {
+		if entry.ObjectStream == nil {
+			return 0, false
+		}
+		streamEntry, found := ctx.Table[*entry.ObjectStream]
+		if !found || streamEntry == nil || streamEntry.Offset == nil {
+			return 0, false
+		}
+		return *streamEntry.Offset, true
+	}
+	if entry.Offset == nil {
+		return 0, false
+	}
+	return *entry.Offset, true
+}
+
+// objectsWrittenAfter returns the numbers of the objects whose current definition was written
+// at or after the given offset, i.e. added or changed by a later incremental update
+func (pa *PDFAnalyzer) objectsWrittenAfter(ctx *model.Context, offset int64) []int {
+	var objNrs []int
+	for objNr, entry := range ctx.Table {
+		if objNr == 0 || entry == nil || entry.Free {
+			continue
+		}
+		if at, ok := objectOffset(ctx, entry); ok && at >= offset {
+			objNrs = append(objNrs, objNr)
+		}
+	}
+	sort.Ints(objNrs)
+	return objNrs
+}
+
+// pageContentObjects returns the object numbers of all page content streams
+func (pa *PDFAnalyzer) pageContentObjects(ctx *model.Context) map[int]bool {
+	contents := make(map[int]bool)
+	for i := 1; i <= ctx.PageCount; i++ {
+		pageDict, _, _, err := ctx.PageDict(i, false)
+		if err != nil || pageDict == nil {
+			continue
+		}
+		obj, found := pageDict.Find("Contents")
+		if !found || obj == nil {
+			continue
+		}
+		if ref, ok := obj.(types.IndirectRef); ok {
+			contents[int(ref.ObjectNumber)] = true
+			obj, _ = ctx.Dereference(ref)
+		}
+		if arr, ok := obj.(types.Array); ok {
+			for _, o := range arr {
+				if ref, ok := o.(types.IndirectRef); ok {
+					contents[int(ref.ObjectNumber)] = true
+				}
+			}
+		}
+	}
+	return contents
+}
+
+// objectKind classifies an object for the modification summary
+func (pa *PDFAnalyzer) objectKind(ctx *model.Context, objNr int, pageContents map[int]bool) string {
+	if pageContents[objNr] {
+		return "page content"
+	}
+	entry, found := ctx.Table[objNr]
+	if !found || entry == nil {
+		return "object"
+	}
+	obj, err := ctx.Dereference(types.IndirectRef{ObjectNumber: types.Integer(objNr)})
+	if err != nil {
+		obj = entry.Object
+	}
+
+	var d types.Dict
+	isStream := false
+	switch v := obj.(type) {
+	case types.Dict:
+		d = v
+	case types.StreamDict:
+		d = v.Dict
+		isStream = true
+	default:
+		return "object"
+	}
+
+	if d.NameEntry("FT") != nil {
+		return "form field"
+	}
+	typeName := ""
+	if t := d.NameEntry("Type"); t != nil {
+		typeName = *t
+	}
+	switch typeName {
+	case "XRef":
+		return "xref"
+	case "ObjStm":
+		return "object stream"
+	case "Annot":
+		return "annotation"
+	case "Page":
+		return "page"
+	case "Pages":
+		return "page tree"
+	case "Catalog":
+		return "catalog"
+	case "Sig", "DocTimeStamp":
+		return "signature"
+	case "Font":
+		return "font"
+	case "XObject":
+		return "XObject"
+	case "Metadata":
+		return "metadata"
+	case "":
+		if d.NameEntry("Subtype") != nil && d.ArrayEntry("Rect") != nil {
+			return "annotation"
+		}
+		if isStream {
+			return "stream"
+		}
+		return "object"
+	}
+	return typeName
+}
+
+// revisionContaining returns the 1-based revision whose end matches a signed range end, i.e.
+// the incremental update that applied the signature; 0 when none does. The signed range usually
+// stops a line ending past %%EOF.
+func revisionContaining(ends []int, end int64) int {
+	for i, revEnd := range ends {
+		if int64(revEnd)+2 >= end {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// orderSignatures sorts the signatures in the order they were applied and records each one's
+// sequence and revision. Signatures and document timestamps are numbered separately;
+// signatures whose byte range is unknown go last.
+func orderSignatures(data []byte, info *PDFInfo) {
+	sort.SliceStable(info.Signatures, func(i, j int) bool {
+		a, b := info.Signatures[i].SignedRangeEnd, info.Signatures[j].SignedRangeEnd
+		if a == 0 || b == 0 {
+			return b == 0 && a != 0
+		}
+		return a < b
+	})
+
+	ends := revisionEnds(data)
+	signatures, timestamps := 0, 0
+	for i := range info.Signatures {
+		sig := &info.Signatures[i]
+		if sig.IsDocumentTimestamp {
+			timestamps++
+			sig.Sequence = timestamps
+		} else {
+			signatures++
+			sig.Sequence = signatures
+		}
+		if sig.SignedRangeEnd > 0 {
+			sig.Revision = revisionContaining(ends, sig.SignedRangeEnd)
+		}
+		if sig.Revision > 0 && sig.Revision <= len(info.Revisions) {
+			rev := &info.Revisions[sig.Revision-1]
+			rev.SignedBy = revisionSigner(*sig)
+			rev.SignatureField = sig.FieldName
+		}
+	}
+}
+
+// revisionSigner names who sealed a revision: the signer, or the timestamp authority of a
+// document timestamp
+func revisionSigner(sig DigitalSignatureInfo) string {
+	if sig.IsDocumentTimestamp {
+		if sig.TimestampAuthority != "" {
+			return "document timestamp by " + sig.TimestampAuthority
+		}
+		return "document timestamp"
+	}
+	if sig.SignerName != "" {
+		return sig.SignerName
+	}
+	if sig.SignerCN != "" {
+		return sig.SignerCN
+	}
+	return "unknown signer"
+}
+
+// analyzeModificationsAfterSigning lists the objects added or changed in revisions after the
+// signature's signed byte range
+func (pa *PDFAnalyzer) analyzeModificationsAfterSigning(ctx *model.Context, data []byte, sr signedRange,
+	pageContents map[int]bool, sigInfo *DigitalSignatureInfo) {
+	end := sr.End()
+	if end <= 0 || end > int64(len(data)) {
+		return
+	}
+	sigInfo.SignedRangeEnd = end
+	sigInfo.ObjectsModifiedAfter = pa.objectsWrittenAfter(ctx, end)
+	sigInfo.IncrementalUpdatesAfterSig = updatesAfter(revisionEnds(data), end)
+
+	counts := make(map[string]int)
+	for _, objNr := range sigInfo.ObjectsModifiedAfter {
+		counts[pa.objectKind(ctx, objNr, pageContents)]++
+	}
+	// Seções xref clássicas não são objetos, mas também fazem parte da atualização
+	if sections := len(xrefSectionPattern.FindAllIndex(data[end:], -1)); sections > 0 {
+		counts["xref"] += sections
+	}
+
+	sigInfo.ModificationsAfterSigning = 0
+	kinds := make([]string, 0, len(counts))
+	for kind, n := range counts {
+		sigInfo.ModificationsAfterSigning += n
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool {
+		if counts[kinds[i]] != counts[kinds[j]] {
+			return counts[kinds[i]] > counts[kinds[j]]
+		}
+		return kinds[i] < kinds[j]
+	})
+	parts := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[kind], pluralize(kind, counts[kind])))
+	}
+	sigInfo.ModificationSummary = strings.Join(parts, ", ")
+	sigInfo.ModifiedAfterSigning = sigInfo.IncrementalUpdatesAfterSig > 0 || sigInfo.ModificationsAfterSigning > 0
+}
+
+// updatesAfter counts the revisions (incremental updates) that end after a signed range end,
+// i.e. were appended after the signature
+func updatesAfter(ends []int, end int64) int {
+	n := 0
+	for _, revEnd := range ends {
+		if int64(revEnd) > end {
+			n++
+		}
+	}
+	return n
+}
+
+// pluralize returns the plural of a kind name for counts other than one
+func pluralize(kind string, n int) string {
+	if n == 1 || strings.HasSuffix(kind, "s") || kind == "metadata" {
+		return kind
+	}
+	return kind + "s"
+}
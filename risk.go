@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Weights of the risk factors; the score is their sum, capped at maxRiskScore
+const (
+	riskJavaScript     = 30
+	riskOpenAction     = 15
+	riskLaunch         = 40
+	riskDangerousFile  = 40
+	riskEmbeddedFile   = 10
+	riskExternalURI    = 10
+	riskSubmitData     = 10
+	riskRemoteDocument = 5
+	riskEncrypted      = 10
+	riskObfuscation    = 15
+	riskHighEntropy    = 10
+	maxRiskScore       = 100
+	riskMediumFrom     = 30 // scores from here are "medium"
+	riskHighFrom       = 60 // scores from here are "high"
+)
+
+// riskAssessment scores how suspicious the document is for malware triage, from 0 to 100, by
+// adding the weights of the signals found: scripts, actions that run on open or reach outside
+// the document, embedded files and programs, and encryption or obfuscated streams that hide
+// content from scanners. Each factor is returned with its weight.
+func riskAssessment(info *PDFInfo) (score int, factors []string) {
+	add := func(weight int, factor string) {
+		score += weight
+		factors = append(factors, fmt.Sprintf("%s (+%d)", factor, weight))
+	}
+
+	if info.HasJavaScript {
+		add(riskJavaScript, "contains JavaScript")
+	}
+	if info.HasOpenAction {
+		add(riskOpenAction, "has OpenAction")
+	}
+
+	// Cada tipo de ação conta uma vez, por mais vezes que apareça
+	actionTypes := make(map[string]bool)
+	for _, a := range info.SuspiciousActions {
+		actionTypes[a.Type] = true
+	}
+	if actionTypes["Launch"] {
+		add(riskLaunch, "launch action (runs a program)")
+	}
+	if actionTypes["URI"] {
+		add(riskExternalURI, "external URI")
+	}
+	if actionTypes["SubmitForm"] || actionTypes["ImportData"] {
+		add(riskSubmitData, "submits or imports form data")
+	}
+	if actionTypes["GoToR"] || actionTypes["GoToE"] {
+		add(riskRemoteDocument, "opens another document")
+	}
+
+	var dangerous []string
+	for _, attachment := range info.Attachments {
+		if attachment.Dangerous {
+			dangerous = append(dangerous, attachment.Name+" ("+attachment.Category+")")
+		}
+	}
+	switch {
+	case len(dangerous) > 0:
+		add(riskDangerousFile, "dangerous attachment: "+strings.Join(dangerous, ", "))
+	case info.HasAttachments:
+		add(riskEmbeddedFile, "embedded files")
+	}
+
+	if info.IsEncrypted {
+		add(riskEncrypted, "encrypted (content hidden from scanners)")
+	}
+	if len(info.UnusualFilterChains) > 0 {
+		add(riskObfuscation, "unusual filter chains (possible obfuscation)")
+	}
+	if len(info.HighEntropyStreams) > 0 {
+		add(riskHighEntropy, "near-random streams (possible hidden payload)")
+	}
+
+	return min(score, maxRiskScore), factors
+}
+
+// riskLevel names the band of a risk score: "low", "medium" or "high"
+func riskLevel(score int) string {
+	switch {
+	case score >= riskHighFrom:
+		return "high"
+	case score >= riskMediumFrom:
+		return "medium"
+	}
+	return "low"
+}
@@ -6,7 +6,7 @@
 set -e  # Exit on any error
 
 echo "🔧 Building PDF analysis program..."
-go build -o pdf-info pdf-info.go
+go build -o pdf-info .
 
 echo "✅ Build completed successfully!"
 echo ""
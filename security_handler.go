@@ -0,0 +1,73 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// standardSecurityHandler is the password-based handler described by the PDF specification;
+// the /P permission bits are only defined for it
+const standardSecurityHandler = "Standard"
+
+// Well-known non-standard security handlers, by encrypt dictionary /Filter
+var knownSecurityHandlers = map[string]string{
+	"Standard":             "password-based",
+	"Adobe.PubSec":         "certificate-based (public key)",
+	"Adobe.APS":            "Adobe rights management (policy server)",
+	"MicrosoftIRMServices": "Microsoft IRM (rights management)",
+}
+
+// Fallback patterns for the encrypt dictionary entries
+var (
+	fallbackFilterPattern    = regexp.MustCompile(`/Filter\s*/([^\s/<>\[\]()]+)`)
+	fallbackSubFilterPattern = regexp.MustCompile(`/SubFilter\s*/([^\s/<>\[\]()]+)`)
+)
+
+// setSecurityHandler records the encrypt dictionary /Filter and /SubFilter, e.g.
+// "Adobe.PubSec (adbe.pkcs7.s5)"
+func setSecurityHandler(info *PDFInfo, filter, subFilter string) {
+	if filter == "" {
+		return
+	}
+	info.SecurityHandler = filter
+	if subFilter != "" {
+		info.SecurityHandler += " (" + subFilter + ")"
+	}
+	info.SecurityHandlerKind = knownSecurityHandlers[filter]
+	if info.SecurityHandlerKind == "" {
+		info.SecurityHandlerKind = "custom"
+	}
+	info.IsRightsManaged = strings.HasPrefix(filter, "MicrosoftIRM") || filter == "Adobe.APS"
+}
+
+// isStandardSecurityHandler reports whether the document uses the standard handler, in which
+// case the permission flags can be interpreted as usual
+func isStandardSecurityHandler(info *PDFInfo) bool {
+	fields := strings.Fields(info.SecurityHandler)
+	return len(fields) == 0 || fields[0] == standardSecurityHandler
+}
+
+// fallbackSecurityHandler reads the handler from the encrypt dictionary referenced by the
+// trailer, for files pdfcpu could not open (typically because it can't decrypt them)
+func fallbackSecurityHandler(objects map[int]string, trailer string, info *PDFInfo) {
+	encryptNr, found := fallbackRef(trailer, "Encrypt")
+	if !found {
+		return
+	}
+	body, ok := objects[encryptNr]
+	if !ok {
+		return
+	}
+	var filter, subFilter string
+	if fm := fallbackFilterPattern.FindStringSubmatch(body); fm != nil {
+		filter = fm[1]
+	}
+	if sm := fallbackSubFilterPattern.FindStringSubmatch(body); sm != nil {
+		subFilter = sm[1]
+	}
+	setSecurityHandler(info, filter, subFilter)
+	if isStandardSecurityHandler(info) {
+		fallbackEncryptionAlgorithm(body, info)
+		info.SecurityWarnings = securityWarnings(info, false)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// maxOverlapSnippet limits how much of the covered text is kept for the report
+const maxOverlapSnippet = 60
+
+// signatureWidget is the visible appearance rectangle of a signature field
+type signatureWidget struct {
+	FieldName string
+	PageNr    int
+	Rect      []float64
+}
+
+// pageNumbersByObject maps page object numbers to page numbers
+func (pa *PDFAnalyzer) pageNumbersByObject(ctx *model.Context) map[int]int {
+	pages := make(map[int]int)
+	for i := 1; i <= ctx.PageCount; i++ {
+		if _, ref, _, err := ctx.PageDict(i, false); err == nil && ref != nil {
+			pages[int(ref.ObjectNumber)] = i
+		}
+	}
+	return pages
+}
+
+// signatureWidgets returns the visible widgets of the signature fields. Invisible signatures
+// (zero-area rectangles) are left out.
+func (pa *PDFAnalyzer) signatureWidgets(ctx *model.Context) []signatureWidget {
+	pages := pa.pageNumbersByObject(ctx)
+
+	// Página de cada anotação, para widgets sem /P
+	annotPages := make(map[int]int)
+	for i := 1; i <= ctx.PageCount; i++ {
+		pageDict, _, _, err := ctx.PageDict(i, false)
+		if err != nil || pageDict == nil {
+			continue
+		}
+		if annotsObj, found := pageDict.Find("Annots"); found {
+			if annots, err := ctx.DereferenceArray(annotsObj); err == nil {
+				for _, a := range annots {
+					if ref, ok := a.(types.IndirectRef); ok {
+						annotPages[int(ref.ObjectNumber)] = i
+					}
+				}
+			}
+		}
+	}
+
+	var widgets []signatureWidget
+	addWidget := func(name string, objNr int, d types.Dict) {
+		rect := pa.rectEntry(ctx, d, "Rect")
+		if rect == nil || rect[0] == rect[2] || rect[1] == rect[3] {
+			return
+		}
+		pageNr := annotPages[objNr]
+		if p, found := d.Find("P"); found {
+			if ref, ok := p.(types.IndirectRef); ok && pages[int(ref.ObjectNumber)] != 0 {
+				pageNr = pages[int(ref.ObjectNumber)]
+			}
+		}
+		if pageNr != 0 {
+			widgets = append(widgets, signatureWidget{FieldName: name, PageNr: pageNr, Rect: rect})
+		}
+	}
+
+	pa.forEachObject(ctx, func(objNr int, obj types.Object) {
+		d, ok := obj.(types.Dict)
+		if !ok {
+			return
+		}
+		if ft := d.NameEntry("FT"); ft == nil || *ft != "Sig" {
+			return
+		}
+		name := getStringFromDict(d, "T")
+
+		// Campo e widget fundidos, ou widgets filhos em /Kids
+		if _, hasRect := d.Find("Rect"); hasRect {
+			addWidget(name, objNr, d)
+			return
+		}
+		kidsObj, found := d.Find("Kids")
+		if !found {
+			return
+		}
+		kids, err := ctx.DereferenceArray(kidsObj)
+		if err != nil {
+			return
+		}
+		for _, kid := range kids {
+			ref, ok := kid.(types.IndirectRef)
+			if !ok {
+				continue
+			}
+			if kidDict, err := ctx.DereferenceDict(ref); err == nil && kidDict != nil {
+				addWidget(name, int(ref.ObjectNumber), kidDict)
+			}
+		}
+	})
+	return widgets
+}
+
+// checkAppearanceOverlap flags a signature whose visible appearance covers page text, which
+// could hide information after signing
+func (pa *PDFAnalyzer) checkAppearanceOverlap(textReader *pdf.Reader, widgets []signatureWidget, sigInfo *DigitalSignatureInfo) {
+	for _, w := range widgets {
+		if w.FieldName == "" || (w.FieldName != sigInfo.FieldName && !strings.HasSuffix(sigInfo.FieldName, "."+w.FieldName)) {
+			continue
+		}
+		sigInfo.AppearancePage = w.PageNr
+		covered := pageTextInRect(textReader, w.PageNr, w.Rect)
+		if strings.TrimSpace(covered) == "" {
+			continue
+		}
+		sigInfo.AppearanceOverlapsText = true
+		if len(covered) > maxOverlapSnippet {
+			covered = covered[:maxOverlapSnippet] + "..."
+		}
+		sigInfo.OverlappedText = covered
+		return
+	}
+}
@@ -1,46 +1,77 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"os"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/ledongthuc/pdf"
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
 )
 
-// analyzeDigitalSignatures analyzes digital signatures in the PDF
-func (pa *PDFAnalyzer) analyzeDigitalSignatures(filePath string, ctx *model.Context, info *PDFInfo) {
-	// First, try to detect signature fields directly from the PDF structure
-	// This works even for encrypted PDFs in many cases
-	hasSignatureFields := pa.detectSignatureFields(ctx, info)
-	
-	// If structural analysis fails, try raw byte analysis
-	if !hasSignatureFields {
-		hasRawSignatures, rawCount, err := pa.detectSignaturesByteAnalysis(filePath)
-		if err != nil {
-			// Silent error - continue with no signatures detected
-		} else if hasRawSignatures {
-			info.HasDigitalSignatures = true
-			info.SignatureCount = rawCount
-			hasSignatureFields = true
-		}
+// validateSignatures validates every signature of the file contents already in memory. It
+// does what api.ValidateSignatures does, which takes a path and would read the file again.
+// pdfcpu still parses the contents a second time: validation needs a context read for the
+// signature command, which refuses encrypted files and optimizes the cross-reference table.
+func (pa *PDFAnalyzer) validateSignatures(data []byte) ([]*model.SignatureValidationResult, error) {
+	conf := pa.pdfConfiguration()
+	conf.Cmd = model.VALIDATESIGNATURE
+	if _, err := api.LoadCertificates(); err != nil {
+		return nil, err
 	}
+
+	rs := bytes.NewReader(data)
+	ctx, err := api.ReadValidateAndOptimize(rs, conf)
+	if err != nil {
+		return nil, err
+	}
+	if len(ctx.Signatures) == 0 && !ctx.SignatureExist && !ctx.AppendOnly {
+		return nil, errors.New("pdfcpu: No signatures present.")
+	}
+	return pdfcpu.ValidateSignatures(rs, ctx, true) // all=true
+}
+
+// analyzeDigitalSignatures analyzes digital signatures in the PDF. The number of signatures
+// comes from the signed signature fields of the AcroForm (signatureFieldCounts); pdfcpu's
+// validation only adds the details of each one, and its count is used only when no signature
+// field was found. Files pdfcpu cannot parse get an estimate from the raw bytes instead
+// (estimateSignatures).
+func (pa *PDFAnalyzer) analyzeDigitalSignatures(filePath string, data []byte, ctx *model.Context, info *PDFInfo) {
+	signatures, timestamps := pa.signatureFieldCounts(ctx)
+	hasSignatureFields := signatures+timestamps > 0
+	info.HasDigitalSignatures = hasSignatureFields
+	info.SignatureCount = signatures
+	info.DocumentTimestampCount = timestamps
 	
 	// Try to validate signatures using pdfcpu (this may fail for encrypted PDFs)
-	results, err := api.ValidateSignatures(filePath, true, nil) // all=true
+	results, err := pa.validateSignatures(data)
+	if err != nil && !hasSignatureFields && strings.Contains(strings.ToLower(err.Error()), "no signatures present") {
+		// Documento sem assinaturas: não é uma falha da análise
+		debugf("no signatures in %s: %v", filePath, err)
+		info.HasDigitalSignatures = false
+		info.SignatureCount = 0
+		info.Completeness.Signatures = completenessFull
+		return
+	}
 	if err != nil {
-		fmt.Printf("Warning: error validating signatures: %v", err)
+		fmt.Fprintf(os.Stderr, "Warning: error validating signatures: %v\n", err)
 		// If validation fails but we detected signature fields, still report them
 		if hasSignatureFields {
 			info.HasDigitalSignatures = true
+			info.Completeness.Signatures = completenessPartial
 			// Keep the signature count from field detection
 			return
 		}
 		info.HasDigitalSignatures = false
 		info.SignatureCount = 0
+		info.Completeness.Signatures = completenessFailed
 		return
 	}
 
@@ -48,20 +79,37 @@ func (pa *PDFAnalyzer) analyzeDigitalSignatures(filePath string, ctx *model.Cont
 		// If no validation results but we found signature fields, report the fields
 		if hasSignatureFields {
 			info.HasDigitalSignatures = true
+			info.Completeness.Signatures = completenessPartial
 			// Keep the signature count from field detection
 			return
 		}
 		info.HasDigitalSignatures = false
 		info.SignatureCount = 0
+		info.Completeness.Signatures = completenessFull
 		return
 	}
+	info.Completeness.Signatures = completenessFull
 
 	// We have successful validation results
-	info.HasDigitalSignatures = true
-	info.SignatureCount = len(results)
 	info.Signatures = make([]DigitalSignatureInfo, 0, len(results))
 
+	// Intervalos assinados, para localizar alterações feitas depois de cada assinatura
+	ranges := pa.signatureByteRanges(ctx)
+	info.SignatureOrderIssues = signatureOrderIssues(ranges)
+	info.SignatureOrderConsistent = len(info.SignatureOrderIssues) == 0
+	pageContents := pa.pageContentObjects(ctx)
+
+	// Texto das páginas, para verificar se a aparência da assinatura cobre conteúdo
+	widgets := pa.signatureWidgets(ctx)
+	var textReader *pdf.Reader
+	if len(widgets) > 0 {
+		if r, err := pa.textReader(data); err == nil {
+			textReader = r
+		}
+	}
+
 	// Process each validation result
+	validatedTimestamps := 0
 	for _, result := range results {
 		sigInfo := DigitalSignatureInfo{
 			FieldName:   result.Details.FieldName,
@@ -98,144 +146,164 @@ func (pa *PDFAnalyzer) analyzeDigitalSignatures(filePath string, ctx *model.Cont
 		}
 
 		// Analyze timestamp information
-		pa.analyzeTimestamp(filePath, &sigInfo)
+		sr, hasRange := findSignedRange(ranges, result.Details.FieldName)
+		pa.analyzeTimestamp(sr.Contents, &sigInfo)
+
+		// Carimbos de tempo do documento não têm signatário
+		if isDocumentTimestamp(result) {
+			markDocumentTimestamp(result, sr.Contents, &sigInfo)
+			validatedTimestamps++
+		} else {
+			signedAt, _ := signatureTime(result)
+			pa.analyzeSignerCertificate(sr.Contents, signedAt, &sigInfo)
+		}
+
+		// Check against the trusted reference time, if one was given
+		if !pa.TrustedTime.IsZero() {
+			pa.checkTrustedTime(result, &sigInfo)
+		}
+
+		// Objects added or changed by incremental updates after this signature
+		if hasRange && data != nil {
+			pa.analyzeModificationsAfterSigning(ctx, data, sr, pageContents, &sigInfo)
+			pa.analyzeByteRangeCoverage(data, sr, &sigInfo)
+		}
+
+		// Visible appearance covering document text
+		if textReader != nil {
+			pa.checkAppearanceOverlap(textReader, widgets, &sigInfo)
+		}
 
 		info.Signatures = append(info.Signatures, sigInfo)
 	}
-}
 
-// detectSignatureFields detects signature fields in the PDF structure
-func (pa *PDFAnalyzer) detectSignatureFields(ctx *model.Context, info *PDFInfo) bool {
-	if ctx == nil || ctx.RootDict == nil {
-		return false
+	// Sem campos de assinatura encontrados, a contagem vem da validação
+	if !hasSignatureFields {
+		info.HasDigitalSignatures = true
+		info.SignatureCount = len(results) - validatedTimestamps
+		info.DocumentTimestampCount = validatedTimestamps
 	}
-	
-	signatureFound := false
-	
-	// Method 1: Look for AcroForm dictionary
-	acroFormObj, found, _ := ctx.RootDict.Entry("AcroForm", "", false)
-	if found && acroFormObj != nil {
-		if count := pa.processAcroForm(ctx, acroFormObj, info); count > 0 {
-			signatureFound = true
+
+	// Ordem de aplicação e revisão de cada assinatura
+	orderSignatures(data, info)
+}
+
+// signatureTime returns when a signature was made: the time of its timestamp token when there
+// is one, otherwise the signing time claimed by the signer. The source names which was used.
+func signatureTime(result *model.SignatureValidationResult) (t time.Time, source string) {
+	for _, signer := range result.Details.Signers {
+		if signer != nil && signer.HasTimestamp && !signer.Timestamp.IsZero() {
+			return signer.Timestamp, "timestamp token"
 		}
 	}
-	
-	// Method 2: Search for signature objects in the PDF structure
-	// Look through all objects for signature-related entries
-	signatureCount := 0
-	
-	// Check if there are any objects with /Type/Sig
-	if ctx.XRefTable != nil {
-		for i := 1; i <= *ctx.XRefTable.Size; i++ {
-			entry, _ := ctx.XRefTable.FindTableEntry(i, 0)
-			if entry == nil || entry.Object == nil {
-				continue
-			}
-			
-			if dict, ok := entry.Object.(types.Dict); ok {
-				// Check for signature type
-				if typeObj, found, _ := dict.Entry("Type", "", false); found {
-					if nameObj, ok := typeObj.(types.Name); ok && string(nameObj) == "Sig" {
-						signatureCount++
-					}
-				}
-				
-				// Check for signature field type
-				if ftObj, found, _ := dict.Entry("FT", "", false); found {
-					if nameObj, ok := ftObj.(types.Name); ok && string(nameObj) == "Sig" {
-						signatureCount++
-					}
-				}
-				
-				// Check for signature value (V) entry that points to signature dict
-				if vObj, found, _ := dict.Entry("V", "", false); found && vObj != nil {
-					if indRef, ok := vObj.(types.IndirectRef); ok {
-						// Try to dereference the signature value
-						if sigDict, err := ctx.Dereference(indRef); err == nil {
-							if sigDictTyped, ok := sigDict.(types.Dict); ok {
-								// Check if this looks like a signature dictionary
-								if filterObj, found, _ := sigDictTyped.Entry("Filter", "", false); found {
-									if nameObj, ok := filterObj.(types.Name); ok {
-										filter := string(nameObj)
-										if filter == "Adobe.PPKLite" || filter == "Adobe.PPKMS" || strings.Contains(filter, "PKCS") {
-											signatureCount++
-										}
-									}
-								}
-							}
-						}
-					}
-				}
-			}
-		}
+	return result.Details.SigningTime, "signing time"
+}
+
+// checkTrustedTime reports whether a signature was made before the trusted reference time.
+// The time of an embedded timestamp token is preferred over the signing time, since the
+// latter is only claimed by the signer while the former is vouched for by a TSA.
+func (pa *PDFAnalyzer) checkTrustedTime(result *model.SignatureValidationResult, sigInfo *DigitalSignatureInfo) {
+	signedAt, source := signatureTime(result)
+	sigInfo.TrustedTimeSource = source
+	if signedAt.IsZero() {
+		sigInfo.SignedBeforeTrustedTime = "Unknown"
+		sigInfo.TrustedTimeSource = ""
+		return
 	}
-	
-	// Method 3: Simple brute force search for signature patterns
-	// This is a fallback when the PDF structure is not easily accessible
-	if !signatureFound && signatureCount == 0 {
-		// Look for signature indicators in the document catalog
-		if pa.hasSignatureIndicators(ctx) {
-			signatureCount = 1 // Assume at least one signature
-		}
+	sigInfo.SignedBeforeTrustedTime = boolToYesNo(signedAt.Before(pa.TrustedTime))
+}
+
+// isDocumentTimestamp reports whether a validation result is a PAdES document timestamp,
+// which timestamps the whole document without attributing authorship
+func isDocumentTimestamp(result *model.SignatureValidationResult) bool {
+	return result.Details.SubFilter == "ETSI.RFC3161"
+}
+
+// markDocumentTimestamp classifies a signature as a document timestamp, taking its time and
+// authority from the timestamp token, which is the whole /Contents
+func markDocumentTimestamp(result *model.SignatureValidationResult, contents []byte, sigInfo *DigitalSignatureInfo) {
+	sigInfo.IsDocumentTimestamp = true
+	sigInfo.Type = "Document Timestamp"
+	sigInfo.HasTimestamp = true
+	sigInfo.TimestampType = "Document timestamp (RFC 3161)"
+	sigInfo.TimestampStatus = "Present"
+	sigInfo.TimestampTime = sigInfo.SigningTime
+	if token, err := parseTimestampToken(contents); err == nil {
+		sigInfo.TimestampTime = formatTime(token.GenTime)
+		sigInfo.TimestampAuthority = token.Authority
+		return
 	}
-	
-	if signatureCount > 0 || signatureFound {
-		info.HasDigitalSignatures = true
-		if signatureCount > info.SignatureCount {
-			info.SignatureCount = signatureCount
+	for _, signer := range result.Details.Signers {
+		if signer != nil && signer.HasTimestamp && !signer.Timestamp.IsZero() {
+			sigInfo.TimestampTime = formatTime(signer.Timestamp)
+			break
 		}
-		return true
 	}
-	
-	return false
 }
 
-// hasSignatureIndicators checks for general signature indicators
-func (pa *PDFAnalyzer) hasSignatureIndicators(ctx *model.Context) bool {
-	// Check for SigFlags in the document catalog or AcroForm
-	if ctx.RootDict != nil {
-		// Look for any reference to signature-related entries
-		if acroFormObj, found, _ := ctx.RootDict.Entry("AcroForm", "", false); found && acroFormObj != nil {
-			// Even if we can't process the AcroForm fully, check for SigFlags
-			if indRef, ok := acroFormObj.(types.IndirectRef); ok {
-				if resolved, err := ctx.Dereference(indRef); err == nil {
-					if dict, ok := resolved.(types.Dict); ok {
-						if sigFlagsObj, found, _ := dict.Entry("SigFlags", "", false); found && sigFlagsObj != nil {
-							return true
-						}
-					}
-				}
-			} else if dict, ok := acroFormObj.(types.Dict); ok {
-				if sigFlagsObj, found, _ := dict.Entry("SigFlags", "", false); found && sigFlagsObj != nil {
-					return true
-				}
+// signatureFieldCounts counts the signed signature fields of the AcroForm, document timestamps
+// apart. A signature dictionary is counted once, however many fields or widgets point to it;
+// unsigned (empty) signature fields are not counted.
+func (pa *PDFAnalyzer) signatureFieldCounts(ctx *model.Context) (signatures, timestamps int) {
+	if ctx == nil || ctx.RootDict == nil {
+		return 0, 0
+	}
+	seen := make(map[int]bool)
+	for _, field := range pa.formFields(ctx) {
+		if field.Type != "Sig" || field.RawValue == nil {
+			continue
+		}
+		if ref, ok := field.RawValue.(types.IndirectRef); ok {
+			if seen[int(ref.ObjectNumber)] {
+				continue
 			}
+			seen[int(ref.ObjectNumber)] = true
+		}
+		sigDict, err := ctx.DereferenceDict(field.RawValue)
+		if err != nil || sigDict == nil {
+			continue
+		}
+		subFilter := sigDict.NameEntry("SubFilter")
+		if t := sigDict.NameEntry("Type"); (t != nil && *t == "DocTimeStamp") || (subFilter != nil && *subFilter == "ETSI.RFC3161") {
+			timestamps++
+		} else {
+			signatures++
 		}
 	}
-	return false
+	return signatures, timestamps
 }
 
-// processAcroForm processes the AcroForm dictionary to find signature fields
-func (pa *PDFAnalyzer) processAcroForm(ctx *model.Context, acroFormObj types.Object, info *PDFInfo) int {
-	// TODO: Implement AcroForm processing for signature field detection
-	// This is a placeholder implementation
-	return 0
+// estimateSignatures estimates the signatures from byte patterns when pdfcpu cannot parse the
+// file; the count is marked as estimated
+func (pa *PDFAnalyzer) estimateSignatures(data []byte, info *PDFInfo) {
+	found, count := pa.detectSignaturesByteAnalysis(data)
+	if !found {
+		return
+	}
+	info.HasDigitalSignatures = true
+	info.SignatureCount = count
+	info.SignatureCountEstimated = true
+	info.Completeness.Signatures = completenessPartial
 }
 
+// Signature dictionaries and signature fields in the raw bytes, with or without spaces
+var (
+	rawSigDictPattern  = regexp.MustCompile(`/Type\s*/Sig\b`)
+	rawSigFieldPattern = regexp.MustCompile(`/FT\s*/Sig\b`)
+)
+
 // detectSignaturesByteAnalysis performs raw byte analysis for signature detection
-func (pa *PDFAnalyzer) detectSignaturesByteAnalysis(filePath string) (bool, int, error) {
-	data, err := ioutil.ReadFile(filePath)
-	if err != nil {
-		return false, 0, err
-	}
-	
+func (pa *PDFAnalyzer) detectSignaturesByteAnalysis(data []byte) (bool, int) {
 	content := string(data)
-	signatureCount := 0
+
+	// Cada assinatura tem um dicionário /Type /Sig e um campo /FT /Sig: não somar os dois
+	dicts := len(rawSigDictPattern.FindAllStringIndex(content, -1))
+	fields := len(rawSigFieldPattern.FindAllStringIndex(content, -1))
+	debugf("found %d signature dictionaries and %d signature fields in the raw bytes", dicts, fields)
+	signatureCount := max(dicts, fields)
 	
 	// Look for signature-related patterns in the raw PDF content
 	patterns := []string{
-		"/Type/Sig",
-		"/FT/Sig", 
 		"/SigFlags",
 		"Adobe.PPKLite",
 		"Adobe.PPKMS",
@@ -253,9 +321,8 @@ func (pa *PDFAnalyzer) detectSignaturesByteAnalysis(filePath string) (bool, int,
 	for _, pattern := range patterns {
 		count := strings.Count(content, pattern)
 		if count > 0 {
-			if pattern == "/Type/Sig" || pattern == "/FT/Sig" {
-				signatureCount += count
-			} else if signatureCount == 0 {
+			debugf("found %d occurrences of %s in the raw bytes", count, pattern)
+			if signatureCount == 0 {
 				signatureCount = 1 // At least one signature indicated
 			}
 		}
@@ -278,37 +345,7 @@ func (pa *PDFAnalyzer) detectSignaturesByteAnalysis(filePath string) (bool, int,
 		}
 	}
 	
-	return signatureCount > 0, signatureCount, nil
-}
-
-// resolveFieldDict resolves a field reference to its dictionary
-func (pa *PDFAnalyzer) resolveFieldDict(ctx *model.Context, fieldRef types.Object) types.Dict {
-	if indRef, ok := fieldRef.(types.IndirectRef); ok {
-		if resolved, err := ctx.Dereference(indRef); err == nil {
-			if dict, ok := resolved.(types.Dict); ok {
-				return dict
-			}
-		}
-	} else if dict, ok := fieldRef.(types.Dict); ok {
-		return dict
-	}
-	return nil
-}
-
-// isSignatureField checks if a field dictionary represents a signature field
-func (pa *PDFAnalyzer) isSignatureField(fieldDict types.Dict) bool {
-	if fieldDict == nil {
-		return false
-	}
-	
-	// Check field type
-	if ftObj, found, _ := fieldDict.Entry("FT", "", false); found {
-		if nameObj, ok := ftObj.(types.Name); ok && string(nameObj) == "Sig" {
-			return true
-		}
-	}
-	
-	return false
+	return signatureCount > 0, signatureCount
 }
 
 // formatTime formats a time.Time to a standard string format
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// ICP-Brasil subjectAltName otherName types (DOC-ICP-04)
+var (
+	oidSubjectAltName  = asn1.ObjectIdentifier{2, 5, 29, 17}
+	oidICPBrasilPF     = asn1.ObjectIdentifier{2, 16, 76, 1, 3, 1} // natural person: birth date, CPF, ...
+	oidICPBrasilPJResp = asn1.ObjectIdentifier{2, 16, 76, 1, 3, 4} // legal entity's responsible person: birth date, CPF, ...
+	oidICPBrasilCNPJ   = asn1.ObjectIdentifier{2, 16, 76, 1, 3, 3} // legal entity: CNPJ
+)
+
+// icpBrasilCPFOffset is where the CPF starts in the PF and PJ-responsible otherName values,
+// after the 8-digit birth date (ddmmyyyy)
+const icpBrasilCPFOffset = 8
+
+// cmsIssuerAndSerial is the SignerIdentifier form naming the signer certificate by issuer and
+// serial number
+type cmsIssuerAndSerial struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+// otherName is the otherName GeneralName form: [0] IMPLICIT SEQUENCE { type-id, [0] EXPLICIT value }.
+// Value is the [0] wrapper; otherNameText unwraps it.
+type otherName struct {
+	TypeID asn1.ObjectIdentifier
+	Value  asn1.RawValue
+}
+
+// cmsCertificates parses the certificates carried in a SignedData
+func cmsCertificates(sd *cmsSignedData) []*x509.Certificate {
+	var certs []*x509.Certificate
+	rest := sd.Certificates.Bytes
+	for len(rest) > 0 {
+		var raw asn1.RawValue
+		var err error
+		rest, err = asn1.Unmarshal(rest, &raw)
+		if err != nil {
+			break
+		}
+		if cert, err := x509.ParseCertificate(raw.FullBytes); err == nil {
+			certs = append(certs, cert)
+		}
+	}
+	return certs
+}
+
+// signerCertificate returns the certificate of the (first) signer of a CMS signature, matched
+// by issuer and serial number or by subject key identifier, along with all the certificates
+// the signature carries (usually the chain up to the root)
+func signerCertificate(contents []byte) (*x509.Certificate, []*x509.Certificate, error) {
+	sd, err := parseSignedData(contents)
+	if err != nil {
+		return nil, nil, err
+	}
+	certs := cmsCertificates(sd)
+	if len(sd.SignerInfos) == 0 || len(certs) == 0 {
+		return nil, nil, errors.New("no signer certificate in the signature")
+	}
+
+	sid := sd.SignerInfos[0].SID
+	for _, cert := range certs {
+		switch {
+		case sid.Class == asn1.ClassUniversal && sid.Tag == asn1.TagSequence:
+			var ias cmsIssuerAndSerial
+			if _, err := asn1.Unmarshal(sid.FullBytes, &ias); err == nil &&
+				bytes.Equal(ias.Issuer.FullBytes, cert.RawIssuer) && ias.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return cert, certs, nil
+			}
+		case sid.Class == asn1.ClassContextSpecific && sid.Tag == 0:
+			// subjectKeyIdentifier [0] IMPLICIT OCTET STRING
+			if bytes.Equal(sid.Bytes, cert.SubjectKeyId) {
+				return cert, certs, nil
+			}
+		}
+	}
+	return nil, nil, errors.New("signer certificate not found among the signature's certificates")
+}
+
+// icpBrasilIdentifiers extracts the CPF and CNPJ of an ICP-Brasil certificate from the
+// subjectAltName otherName entries; both are "" for other certificates
+func icpBrasilIdentifiers(cert *x509.Certificate) (cpf, cnpj string) {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidSubjectAltName) {
+			continue
+		}
+		var names []asn1.RawValue
+		if _, err := asn1.Unmarshal(ext.Value, &names); err != nil {
+			return "", ""
+		}
+		for _, name := range names {
+			if name.Class != asn1.ClassContextSpecific || name.Tag != 0 {
+				continue
+			}
+			var on otherName
+			// A forma IMPLICIT esconde o SEQUENCE: reconstruí-lo para decodificar
+			seq := asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSequence, IsCompound: true, Bytes: name.Bytes}
+			full, err := asn1.Marshal(seq)
+			if err != nil {
+				continue
+			}
+			if _, err := asn1.Unmarshal(full, &on); err != nil {
+				continue
+			}
+			value := digitsOnly(otherNameText(on.Value))
+			switch {
+			case on.TypeID.Equal(oidICPBrasilPF), on.TypeID.Equal(oidICPBrasilPJResp):
+				if len(value) >= icpBrasilCPFOffset+11 && cpf == "" {
+					cpf = value[icpBrasilCPFOffset : icpBrasilCPFOffset+11]
+				}
+			case on.TypeID.Equal(oidICPBrasilCNPJ):
+				if len(value) >= 14 {
+					cnpj = value[:14]
+				}
+			}
+		}
+	}
+	// CPF "zerado" indica ausência do dado
+	if strings.Trim(cpf, "0") == "" {
+		cpf = ""
+	}
+	return cpf, cnpj
+}
+
+// otherNameText returns the text of an otherName value, which ICP-Brasil encodes as an OCTET
+// STRING, PrintableString or UTF8String; all of them hold the characters as their content
+func otherNameText(wrapper asn1.RawValue) string {
+	var inner asn1.RawValue
+	if _, err := asn1.Unmarshal(wrapper.Bytes, &inner); err != nil {
+		return ""
+	}
+	return string(inner.Bytes)
+}
+
+// digitsOnly keeps the leading run of ASCII digits of s
+func digitsOnly(s string) string {
+	for i, r := range s {
+		if r < '0' || r > '9' {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// nameOrganization returns the first organization of a certificate name
+func nameOrganization(name pkix.Name) string {
+	if len(name.Organization) > 0 {
+		return name.Organization[0]
+	}
+	return ""
+}
+
+// loadCABundle reads the PEM certificates of a CA bundle file into a pool
+func loadCABundle(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", path)
+	}
+	return pool, nil
+}
+
+// verifyCertificateChain checks that the signer certificate chains to a trusted root through
+// the certificates carried in the signature, that every certificate was valid at signing time
+// and that the signer certificate may be used for signing. It returns nil when the chain is
+// trusted.
+func verifyCertificateChain(cert *x509.Certificate, certs []*x509.Certificate, roots *x509.CertPool, signedAt time.Time) error {
+	// Sem a chave digitalSignature ou nonRepudiation o certificado não serve para assinar
+	if cert.KeyUsage != 0 && cert.KeyUsage&(x509.KeyUsageDigitalSignature|x509.KeyUsageContentCommitment) == 0 {
+		return errors.New("the certificate's key usage does not allow signing")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, c := range certs {
+		if c != cert {
+			intermediates.AddCert(c)
+		}
+	}
+	if signedAt.IsZero() {
+		signedAt = time.Now()
+	}
+	_, err := cert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   signedAt,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
+// analyzeSignerCertificate fills the signer certificate details of a CMS signature and checks
+// its chain against the trust store (--ca-bundle, or the system roots). Signatures that are
+// not CMS (adbe.x509.rsa_sha1) carry no certificate in /Contents and are left as is.
+func (pa *PDFAnalyzer) analyzeSignerCertificate(contents []byte, signedAt time.Time, sigInfo *DigitalSignatureInfo) {
+	if len(contents) == 0 {
+		return
+	}
+	cert, certs, err := signerCertificate(contents)
+	if err != nil {
+		return
+	}
+	sigInfo.SignerCN = cert.Subject.CommonName
+	sigInfo.SignerOrg = nameOrganization(cert.Subject)
+	sigInfo.IssuerCN = cert.Issuer.CommonName
+	sigInfo.CertSerialNumber = fmt.Sprintf("%X", cert.SerialNumber)
+	sigInfo.CertNotBefore = formatTime(cert.NotBefore)
+	sigInfo.CertNotAfter = formatTime(cert.NotAfter)
+	sigInfo.SignerCPF, sigInfo.SignerCNPJ = icpBrasilIdentifiers(cert)
+	if sigInfo.SignerName == "" {
+		sigInfo.SignerName = sigInfo.SignerCN
+	}
+
+	// nil usa as raízes do sistema
+	if err := verifyCertificateChain(cert, certs, pa.TrustRoots, signedAt); err != nil {
+		sigInfo.ChainError = err.Error()
+	} else {
+		sigInfo.ChainTrusted = true
+	}
+}
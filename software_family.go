@@ -0,0 +1,70 @@
+package main
+
+import "strings"
+
+// softwareFamilies map fragments of the /Creator and /Producer strings to the canonical name of
+// the program family. The first match wins, so more specific fragments come first (WordPerfect
+// before Word, TCPDF before FPDF, "PDFMaker for Word" counts as Word).
+var softwareFamilies = []struct {
+	fragment string
+	family   string
+}{
+	{"microsoft: print to pdf", "Microsoft Print to PDF"},
+	{"wordperfect", "WordPerfect"},
+	{"word", "Word"},
+	{"excel", "Excel"},
+	{"powerpoint", "PowerPoint"},
+	{"libreoffice", "LibreOffice"},
+	{"openoffice", "OpenOffice"},
+	{"google docs", "Google Docs"},
+	{"indesign", "InDesign"},
+	{"illustrator", "Illustrator"},
+	{"photoshop", "Photoshop"},
+	{"acrobat", "Acrobat"},
+	{"distiller", "Acrobat"},
+	{"adobe pdf library", "Adobe PDF Library"},
+	{"itext", "iText"},
+	{"openpdf", "OpenPDF"},
+	{"ghostscript", "Ghostscript"},
+	{"tcpdf", "TCPDF"},
+	{"fpdf", "FPDF"},
+	{"wkhtmltopdf", "wkhtmltopdf"},
+	{"skia/pdf", "Chrome (Skia)"},
+	{"quartz pdfcontext", "macOS Quartz"},
+	{"pdftex", "TeX"},
+	{"xetex", "TeX"},
+	{"luatex", "TeX"},
+	{"dvipdfm", "TeX"},
+	{"pdfsharp", "PDFsharp"},
+	{"reportlab", "ReportLab"},
+	{"pdfbox", "PDFBox"},
+	{"pdfium", "PDFium"},
+	{"pdfcpu", "pdfcpu"},
+	{"pypdf", "pypdf"},
+	{"aspose", "Aspose"},
+	{"prince", "Prince"},
+	{"foxit", "Foxit"},
+	{"nitro", "Nitro"},
+	{"cairo", "cairo"},
+}
+
+// softwareFamily returns the canonical family of a /Creator or /Producer string, e.g. iText for
+// "iTextSharp 5.5.13 ©2000-2018 iText Group NV"; empty when the program is not in the table
+func softwareFamily(software string) string {
+	lower := strings.ToLower(software)
+	if strings.TrimSpace(lower) == "" {
+		return ""
+	}
+	for _, s := range softwareFamilies {
+		if strings.Contains(lower, s.fragment) {
+			return s.family
+		}
+	}
+	return ""
+}
+
+// setSoftwareFamilies fills CreatorFamily and ProducerFamily from the raw strings
+func setSoftwareFamilies(info *PDFInfo) {
+	info.CreatorFamily = softwareFamily(info.Creator)
+	info.ProducerFamily = softwareFamily(info.Producer)
+}
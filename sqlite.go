@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sqliteTable is the table --sqlite appends one row per analyzed file to
+const sqliteTable = "pdf_files"
+
+// sqliteColumn is one column of the row written for a file
+type sqliteColumn struct {
+	Name    string
+	SQLType string
+	Value   string // SQL literal
+}
+
+// sqliteSchema lists the columns of the table, named after the report fields in snake_case.
+// The list is explicit so that the schema stays the same when PDFInfo changes: new columns go
+// at the end, and writeSQLite adds them to tables created by an earlier version.
+var sqliteSchema = []struct {
+	Name    string
+	SQLType string
+	Value   func(info *PDFInfo) string // SQL literal
+}{
+	{"file_name", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.FileName) }},
+	{"file_path", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.FilePath) }},
+	{"file_size", "INTEGER", func(info *PDFInfo) string { return sqlInt(info.FileSize) }},
+	{"last_modified", "TEXT", func(info *PDFInfo) string { return sqlTime(info.LastModified) }},
+	{"hashes", "TEXT", func(info *PDFInfo) string { return sqlJSON(info.Hashes) }},
+	{"title", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.Title) }},
+	{"author", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.Author) }},
+	{"subject", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.Subject) }},
+	{"keywords", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.Keywords) }},
+	{"creator", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.Creator) }},
+	{"producer", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.Producer) }},
+	{"creator_family", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.CreatorFamily) }},
+	{"producer_family", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.ProducerFamily) }},
+	{"creation_date", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.CreationDate) }},
+	{"mod_date", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.ModDate) }},
+	{"creation_date_parsed", "TEXT", func(info *PDFInfo) string { return sqlTime(info.CreationDateParsed) }},
+	{"mod_date_parsed", "TEXT", func(info *PDFInfo) string { return sqlTime(info.ModDateParsed) }},
+	{"trapped", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.Trapped) }},
+	{"custom_metadata", "TEXT", func(info *PDFInfo) string { return sqlJSON(info.CustomMetadata) }},
+	{"xmp_present", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.XMPPresent) }},
+	{"has_no_metadata", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.HasNoMetadata) }},
+	{"fallback_parsing", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.FallbackParsing) }},
+	{"is_truncated", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.IsTruncated) }},
+	{"pdf_version", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.PDFVersion) }},
+	{"header_version", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.HeaderVersion) }},
+	{"revision_count", "INTEGER", func(info *PDFInfo) string { return sqlInt(int64(info.RevisionCount)) }},
+	{"document_id", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.DocumentID) }},
+	{"revision_id", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.RevisionID) }},
+	{"id_changed", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.IDChanged) }},
+	{"page_count", "INTEGER", func(info *PDFInfo) string { return sqlInt(int64(info.PageCount)) }},
+	{"is_encrypted", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.IsEncrypted) }},
+	{"is_linearized", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.IsLinearized) }},
+	{"is_tagged", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.IsTagged) }},
+	{"declared_language", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.DeclaredLanguage) }},
+	{"pdfua_conformance", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.PDFUAConformance) }},
+	{"has_bookmarks", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.HasBookmarks) }},
+	{"has_attachments", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.HasAttachments) }},
+	{"has_forms", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.HasForms) }},
+	{"has_xfa", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.HasXFA) }},
+	{"has_java_script", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.HasJavaScript) }},
+	{"has_open_action", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.HasOpenAction) }},
+	{"suspicious_actions", "TEXT", func(info *PDFInfo) string { return sqlJSON(info.SuspiciousActions) }},
+	{"risk_score", "INTEGER", func(info *PDFInfo) string { return sqlInt(int64(info.RiskScore)) }},
+	{"risk_factors", "TEXT", func(info *PDFInfo) string { return sqlJSON(info.RiskFactors) }},
+	{"has_annotations", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.HasAnnotations) }},
+	{"has_layers", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.HasLayers) }},
+	{"has_transparency", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.HasTransparency) }},
+	{"security_handler", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.SecurityHandler) }},
+	{"encryption_algorithm", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.EncryptionAlgorithm) }},
+	{"encryption_key_bits", "INTEGER", func(info *PDFInfo) string { return sqlInt(int64(info.EncryptionKeyBits)) }},
+	{"print_allowed", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.PrintAllowed) }},
+	{"modify_allowed", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.ModifyAllowed) }},
+	{"copy_allowed", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.CopyAllowed) }},
+	{"has_digital_signatures", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.HasDigitalSignatures) }},
+	{"signature_count", "INTEGER", func(info *PDFInfo) string { return sqlInt(int64(info.SignatureCount)) }},
+	{"document_timestamp_count", "INTEGER", func(info *PDFInfo) string { return sqlInt(int64(info.DocumentTimestampCount)) }},
+	{"signatures", "TEXT", func(info *PDFInfo) string { return sqlJSON(info.Signatures) }},
+	{"total_text_length", "INTEGER", func(info *PDFInfo) string { return sqlInt(int64(info.TotalTextLength)) }},
+	{"total_word_count", "INTEGER", func(info *PDFInfo) string { return sqlInt(int64(info.TotalWordCount)) }},
+	{"detected_language", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.DetectedLanguage) }},
+	{"fonts_used", "TEXT", func(info *PDFInfo) string { return sqlJSON(info.FontsUsed) }},
+	{"all_fonts_embedded", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.AllFontsEmbedded) }},
+	{"images_count", "INTEGER", func(info *PDFInfo) string { return sqlInt(int64(info.ImagesCount)) }},
+	{"color_page_count", "INTEGER", func(info *PDFInfo) string { return sqlInt(int64(info.ColorPageCount)) }},
+	{"black_white_page_count", "INTEGER", func(info *PDFInfo) string { return sqlInt(int64(info.BlackWhitePageCount)) }},
+	{"color_spaces", "TEXT", func(info *PDFInfo) string { return sqlJSON(info.ColorSpaces) }},
+	{"spot_colors", "TEXT", func(info *PDFInfo) string { return sqlJSON(info.SpotColors) }},
+	{"is_probably_scanned", "INTEGER", func(info *PDFInfo) string { return sqlBool(info.IsProbablyScanned) }},
+	{"document_class", "TEXT", func(info *PDFInfo) string { return sqlQuote(info.DocumentClass) }},
+	{"pages", "TEXT", func(info *PDFInfo) string { return sqlJSON(info.Pages) }},
+	{"attachments", "TEXT", func(info *PDFInfo) string { return sqlJSON(info.Attachments) }},
+}
+
+// sqliteColumns returns the row written for a file: the columns of sqliteSchema with their values
+func sqliteColumns(info *PDFInfo) []sqliteColumn {
+	columns := make([]sqliteColumn, len(sqliteSchema))
+	for i, col := range sqliteSchema {
+		columns[i] = sqliteColumn{Name: col.Name, SQLType: col.SQLType, Value: col.Value(info)}
+	}
+	return columns
+}
+
+// sqlInt returns n as an SQL integer literal
+func sqlInt(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
+
+// sqlBool returns b as 0 or 1, SQLite having no boolean type
+func sqlBool(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// sqlTime returns t as an RFC3339 string literal, or NULL when it is unknown
+func sqlTime(t time.Time) string {
+	if t.IsZero() {
+		return "NULL"
+	}
+	return sqlQuote(t.Format(time.RFC3339))
+}
+
+// sqlJSON returns v as JSON text, or NULL for a nil list or map
+func sqlJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return "NULL"
+	}
+	return sqlQuote(string(data))
+}
+
+// sqlQuote returns s as an SQL string literal
+func sqlQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// writeSQLite inserts a row for the analyzed file into the SQLite database at dbPath, creating
+// the table (and any columns added since it was created) as needed. The sqlite3 command-line
+// tool does the writing, so it must be installed.
+func writeSQLite(dbPath string, info *PDFInfo) error {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		return fmt.Errorf("sqlite3 command not found in PATH: %v", err)
+	}
+
+	existing, err := sqliteExistingColumns(dbPath)
+	if err != nil {
+		return err
+	}
+
+	columns := sqliteColumns(info)
+	var sql strings.Builder
+	sql.WriteString("BEGIN;\n")
+	if len(existing) == 0 {
+		defs := []string{"id INTEGER PRIMARY KEY AUTOINCREMENT", "analyzed_at TEXT"}
+		for _, col := range columns {
+			defs = append(defs, col.Name+" "+col.SQLType)
+		}
+		fmt.Fprintf(&sql, "CREATE TABLE IF NOT EXISTS %s (\n  %s\n);\n", sqliteTable, strings.Join(defs, ",\n  "))
+	} else {
+		// Tabela criada por uma versão anterior: acrescentar as colunas novas
+		for _, col := range columns {
+			if !existing[col.Name] {
+				fmt.Fprintf(&sql, "ALTER TABLE %s ADD COLUMN %s %s;\n", sqliteTable, col.Name, col.SQLType)
+			}
+		}
+	}
+
+	names := []string{"analyzed_at"}
+	values := []string{sqlQuote(time.Now().Format(time.RFC3339))}
+	for _, col := range columns {
+		names = append(names, col.Name)
+		values = append(values, col.Value)
+	}
+	fmt.Fprintf(&sql, "INSERT INTO %s (%s) VALUES (%s);\n", sqliteTable, strings.Join(names, ", "), strings.Join(values, ", "))
+	sql.WriteString("COMMIT;\n")
+
+	cmd := exec.Command("sqlite3", "-bail", dbPath)
+	cmd.Stdin = strings.NewReader(sql.String())
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("sqlite3 failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// sqliteExistingColumns returns the columns of the table, or an empty set if it does not exist yet
+func sqliteExistingColumns(dbPath string) (map[string]bool, error) {
+	cmd := exec.Command("sqlite3", dbPath, fmt.Sprintf("PRAGMA table_info(%s);", sqliteTable))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("sqlite3 failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	// Cada linha: cid|name|type|notnull|dflt_value|pk
+	columns := make(map[string]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		if parts := strings.Split(line, "|"); len(parts) > 1 {
+			columns[parts[1]] = true
+		}
+	}
+	return columns, nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// standardStructureTypes lists the standard structure types of PDF 1.7 and PDF 2.0
+var standardStructureTypes = map[string]bool{
+	// Grouping
+	"Document": true, "DocumentFragment": true, "Part": true, "Art": true, "Sect": true, "Div": true,
+	"Aside": true, "BlockQuote": true, "Caption": true, "TOC": true, "TOCI": true, "Index": true,
+	"NonStruct": true, "Private": true,
+	// Block-level
+	"P": true, "H": true, "H1": true, "H2": true, "H3": true, "H4": true, "H5": true, "H6": true,
+	"Title": true, "FENote": true,
+	"L": true, "LI": true, "Lbl": true, "LBody": true,
+	"Table": true, "TR": true, "TH": true, "TD": true, "THead": true, "TBody": true, "TFoot": true,
+	// Inline-level
+	"Span": true, "Quote": true, "Note": true, "Reference": true, "BibEntry": true, "Code": true,
+	"Link": true, "Annot": true, "Sub": true, "Em": true, "Strong": true,
+	"Ruby": true, "RB": true, "RT": true, "RP": true, "Warichu": true, "WT": true, "WP": true,
+	// Illustration
+	"Figure": true, "Formula": true, "Form": true, "Artifact": true,
+}
+
+// maxStructureElements bounds the structure tree walk on huge or malformed documents
+const maxStructureElements = 100000
+
+// structureMCIDs records, per page object number, the marked-content IDs referenced by the structure tree
+type structureMCIDs map[int]map[int]bool
+
+// add records that the structure tree references mcid on the page with object number pageObjNr
+func (m structureMCIDs) add(pageObjNr, mcid int) {
+	if m[pageObjNr] == nil {
+		m[pageObjNr] = make(map[int]bool)
+	}
+	m[pageObjNr][mcid] = true
+}
+
+// analyzeStructureTree reads the /RoleMap of the structure tree root and flags the custom
+// structure types used in the tree that do not map to a standard type. It returns the
+// marked-content IDs the tree references, or nil when the document has no structure tree.
+func (pa *PDFAnalyzer) analyzeStructureTree(ctx *model.Context, info *PDFInfo) structureMCIDs {
+	rootObj, found := ctx.RootDict.Find("StructTreeRoot")
+	if !found || rootObj == nil {
+		return nil
+	}
+	structRoot, err := ctx.DereferenceDict(rootObj)
+	if err != nil || structRoot == nil {
+		return nil
+	}
+	info.HasStructTree = true
+
+	// Mapeamento de tipos personalizados
+	if roleMapObj, found := structRoot.Find("RoleMap"); found && roleMapObj != nil {
+		if roleMap, err := ctx.DereferenceDict(roleMapObj); err == nil && roleMap != nil {
+			info.RoleMap = make(map[string]string)
+			for custom := range roleMap {
+				if target := getStringFromDict(roleMap, custom); target != "" {
+					info.RoleMap[custom] = target
+				}
+			}
+		}
+	}
+
+	// Percorrer a árvore coletando os tipos usados e os MCIDs referenciados
+	usedTypes := make(map[string]bool)
+	mcids := make(structureMCIDs)
+	visited := make(map[int]bool)
+	count := 0
+	var walk func(obj types.Object, page int, depth int)
+	walk = func(obj types.Object, page int, depth int) {
+		if obj == nil || depth > 256 || count >= maxStructureElements {
+			return
+		}
+		// Um inteiro em /K é um MCID no conteúdo da página herdada
+		if mcid, ok := obj.(types.Integer); ok {
+			if page != 0 {
+				mcids.add(page, int(mcid))
+			}
+			return
+		}
+		if ref, ok := obj.(types.IndirectRef); ok {
+			if visited[int(ref.ObjectNumber)] {
+				return
+			}
+			visited[int(ref.ObjectNumber)] = true
+		}
+		resolved, err := ctx.Dereference(obj)
+		if err != nil || resolved == nil {
+			return
+		}
+
+		switch v := resolved.(type) {
+		case types.Array:
+			for _, kid := range v {
+				walk(kid, page, depth+1)
+			}
+		case types.Dict:
+			if pg, ok := v.Find("Pg"); ok {
+				if ref, ok := pg.(types.IndirectRef); ok {
+					page = int(ref.ObjectNumber)
+				}
+			}
+			// Referências a conteúdo marcado (MCR) e objetos (OBJR) não são elementos
+			if t := v.NameEntry("Type"); t != nil && (*t == "MCR" || *t == "OBJR") {
+				if mcid := v.IntEntry("MCID"); *t == "MCR" && mcid != nil && page != 0 {
+					mcids.add(page, *mcid)
+				}
+				return
+			}
+			count++
+			if s := v.NameEntry("S"); s != nil {
+				usedTypes[*s] = true
+			}
+			if kids, found := v.Find("K"); found {
+				walk(kids, page, depth+1)
+			}
+		}
+	}
+	if kids, found := structRoot.Find("K"); found {
+		walk(kids, 0, 0)
+	}
+
+	for structType := range usedTypes {
+		if !isMappedStructureType(structType, info.RoleMap) {
+			info.UnmappedStructureTypes = append(info.UnmappedStructureTypes, structType)
+		}
+	}
+	sort.Strings(info.UnmappedStructureTypes)
+
+	return mcids
+}
+
+// isMappedStructureType reports whether a structure type is standard or reaches a standard
+// type by following the role map (which may chain through other custom types)
+func isMappedStructureType(structType string, roleMap map[string]string) bool {
+	seen := make(map[string]bool)
+	for current := structType; !seen[current]; {
+		if standardStructureTypes[current] {
+			return true
+		}
+		seen[current] = true
+		next, ok := roleMap[current]
+		if !ok {
+			return false
+		}
+		current = next
+	}
+	return false
+}
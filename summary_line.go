@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Widths of the --summary columns after the file name, wide enough for typical values
+const (
+	summaryPagesWidth      = 10 // "1234 pages"
+	summaryVersionWidth    = 3  // "1.7"
+	summaryEncryptionWidth = 9  // "encrypted"
+	summarySignaturesWidth = 34 // "12 sigs (1 invalid, 10 unverified)"
+)
+
+// countLabel returns "1 page", "2 pages" and so on
+func countLabel(n int, singular, plural string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+// signatureSummary describes the signatures for --summary: "unsigned", "1 sig", or
+// "3 sigs (1 invalid, 1 unverified)"; document timestamps do not count toward the total.
+// Invalid follows the exit status rule (Status "Invalid"); unverified are those pdfcpu could
+// not decide on (Status "Unknown").
+func signatureSummary(info *PDFInfo) string {
+	if info.SignatureCount == 0 {
+		return "unsigned"
+	}
+	label := countLabel(info.SignatureCount, "sig", "sigs")
+	if info.SignatureCountEstimated {
+		return label + " (estimated)"
+	}
+	invalid, unverified := 0, 0
+	for _, sig := range info.Signatures {
+		switch {
+		case sig.Status == "Invalid":
+			invalid++
+		case sig.Status == "Unknown" && !sig.IsDocumentTimestamp:
+			unverified++
+		}
+	}
+	var notes []string
+	if invalid > 0 {
+		notes = append(notes, fmt.Sprintf("%d invalid", invalid))
+	}
+	if unverified > 0 {
+		notes = append(notes, fmt.Sprintf("%d unverified", unverified))
+	}
+	if len(notes) > 0 {
+		label += " (" + strings.Join(notes, ", ") + ")"
+	}
+	return label
+}
+
+// summaryLine formats the --summary line of a file, with the name padded to nameWidth so that
+// the columns of consecutive lines line up, e.g.
+// "doc.pdf  12 pages  1.7  encrypted  2 sigs (1 invalid)  2.3 MB"
+func summaryLine(name string, info *PDFInfo, nameWidth int) string {
+	encryption := ""
+	if info.IsEncrypted {
+		encryption = "encrypted"
+	}
+	return fmt.Sprintf("%-*s  %*s  %-*s  %-*s  %-*s  %s",
+		nameWidth, name,
+		summaryPagesWidth, countLabel(info.PageCount, "page", "pages"),
+		summaryVersionWidth, info.PDFVersion,
+		summaryEncryptionWidth, encryption,
+		summarySignaturesWidth, signatureSummary(info),
+		info.FileSizeHuman)
+}
+
+// summaryNameWidth returns the width of the name column: the longest of the paths
+func summaryNameWidth(paths []string) int {
+	width := 0
+	for _, path := range paths {
+		width = max(width, len([]rune(path)))
+	}
+	return width
+}
+
+// writeSummaryLine writes the --summary line of a file to w
+func writeSummaryLine(w io.Writer, path string, info *PDFInfo, nameWidth int) error {
+	_, err := fmt.Fprintln(w, strings.TrimRight(summaryLine(path, info, nameWidth), " "))
+	return err
+}
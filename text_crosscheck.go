@@ -0,0 +1,120 @@
+package main
+
+import (
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// Two text lengths "disagree" when the larger is at least textDiscrepancyRatio times the smaller
+// and at least textDiscrepancyMinLength characters long
+const (
+	textDiscrepancyRatio     = 2
+	textDiscrepancyMinLength = 100
+)
+
+// countContentStreamText counts the glyphs shown by the text operators (Tj, TJ, ', ") of all
+// page content streams, as an extraction-independent estimate of the text length
+func (pa *PDFAnalyzer) countContentStreamText(ctx *model.Context) int {
+	total := 0
+	for i := 1; i <= ctx.PageCount; i++ {
+		pageDict, _, inherited, err := ctx.PageDict(i, true)
+		if err != nil || pageDict == nil {
+			continue
+		}
+		content, err := ctx.PageContent(pageDict, i)
+		if err != nil {
+			continue
+		}
+		resources := pa.pageDictResources(ctx, pageDict, inherited)
+
+		bytesPerGlyph := 1
+		for _, op := range parseContentOps(content) {
+			switch op.Operator {
+			case "Tf":
+				bytesPerGlyph = 1
+				if len(op.Operands) == 0 {
+					continue
+				}
+				// Fontes compostas (Type0) usam códigos de dois bytes
+				if font, err := ctx.DereferenceDict(pa.namedResource(ctx, resources, "Font", op.Operands[0])); err == nil && font != nil {
+					if subtype := font.NameEntry("Subtype"); subtype != nil && *subtype == "Type0" {
+						bytesPerGlyph = 2
+					}
+				}
+			case "Tj", "'", "\"":
+				if len(op.Operands) > 0 {
+					total += stringTokenLength(op.Operands[len(op.Operands)-1]) / bytesPerGlyph
+				}
+			case "TJ":
+				if len(op.Operands) == 0 {
+					continue
+				}
+				array := op.Operands[len(op.Operands)-1]
+				if len(array) < 2 || array[0] != '[' {
+					continue
+				}
+				_, elements := scanContentOps([]byte(array[1 : len(array)-1]))
+				for _, element := range elements {
+					total += stringTokenLength(element) / bytesPerGlyph
+				}
+			}
+		}
+	}
+	return total
+}
+
+// stringTokenLength returns the number of bytes encoded by a literal "(...)" or hex "<...>"
+// string token, or 0 for any other token
+func stringTokenLength(token string) int {
+	if len(token) < 2 {
+		return 0
+	}
+	switch token[0] {
+	case '<':
+		digits := 0
+		for i := 1; i < len(token) && token[i] != '>'; i++ {
+			if !isContentWhitespace(token[i]) {
+				digits++
+			}
+		}
+		return (digits + 1) / 2
+	case '(':
+		n := 0
+		inner := token[1 : len(token)-1]
+		for i := 0; i < len(inner); i++ {
+			if inner[i] != '\\' || i+1 >= len(inner) {
+				n++
+				continue
+			}
+			i++
+			switch {
+			case inner[i] == '\r' || inner[i] == '\n':
+				// Continuação de linha: não produz bytes
+				if inner[i] == '\r' && i+1 < len(inner) && inner[i+1] == '\n' {
+					i++
+				}
+			case inner[i] >= '0' && inner[i] <= '7':
+				for j := 0; j < 2 && i+1 < len(inner) && inner[i+1] >= '0' && inner[i+1] <= '7'; j++ {
+					i++
+				}
+				n++
+			default:
+				n++
+			}
+		}
+		return n
+	}
+	return 0
+}
+
+// textLengthsDisagree reports whether two text length estimates differ enough to suggest that
+// text extraction depends on the parser for this file
+func textLengthsDisagree(a, b int) bool {
+	larger, smaller := a, b
+	if smaller > larger {
+		larger, smaller = smaller, larger
+	}
+	if larger < textDiscrepancyMinLength {
+		return false
+	}
+	return smaller == 0 || larger >= textDiscrepancyRatio*smaller
+}
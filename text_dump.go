@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// pageSeparator marks where each page starts in the --dump-text output
+func pageSeparator(n int) string {
+	return fmt.Sprintf("--- Page %d ---", n)
+}
+
+// writeFileText writes the page text of one file; with several files, each one's text is
+// preceded by its path
+func (pa *PDFAnalyzer) writeFileText(w io.Writer, path string, info *PDFInfo, labeled bool) error {
+	if labeled {
+		if _, err := fmt.Fprintf(w, "=== %s ===\n", path); err != nil {
+			return err
+		}
+	}
+	return pa.WriteText(w, info)
+}
+
+// WriteText writes the extracted plain text of every page to w, each page preceded by its
+// separator line. Requires KeepText during the analysis.
+func (pa *PDFAnalyzer) WriteText(w io.Writer, info *PDFInfo) error {
+	ew := &errWriter{w: w}
+	for _, page := range info.Pages {
+		fmt.Fprintln(ew, pageSeparator(page.Number))
+		if text := strings.TrimRight(page.Text, "\n"); text != "" {
+			fmt.Fprintln(ew, text)
+		}
+	}
+	return ew.err
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// isLogographic reports whether r belongs to a script written without spaces between words
+// (Chinese, Japanese kana), where each character is counted as a word
+func isLogographic(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
+
+// countWords counts the words of extracted text: runs of characters between whitespace that
+// contain a letter or digit (bullets and "____" fill-in lines are not words), plus one word per
+// Chinese or Japanese character
+func countWords(text string) int {
+	words := 0
+	inWord, hasAlnum := false, false
+	endWord := func() {
+		if inWord && hasAlnum {
+			words++
+		}
+		inWord, hasAlnum = false, false
+	}
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+			endWord()
+		case isLogographic(r):
+			endWord()
+			words++
+		default:
+			inWord = true
+			hasAlnum = hasAlnum || unicode.IsLetter(r) || unicode.IsDigit(r)
+		}
+	}
+	endWord()
+	return words
+}
+
+// countLines counts the non-blank lines of extracted text
+func countLines(text string) int {
+	lines := 0
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines++
+		}
+	}
+	return lines
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"time"
+)
+
+// OIDs used to locate RFC 3161 timestamp tokens in CMS signatures
+var (
+	oidSignedData         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo            = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidSignatureTimeStamp = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 14}
+)
+
+// CMS (RFC 5652) structures, only as far as needed to reach the timestamp token
+type cmsContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+type cmsSignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo cmsEncapContentInfo
+	Certificates     asn1.RawValue   `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue   `asn1:"optional,tag:1"`
+	SignerInfos      []cmsSignerInfo `asn1:"set"`
+}
+
+type cmsEncapContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+type cmsSignerInfo struct {
+	Version            int
+	SID                asn1.RawValue
+	DigestAlgorithm    asn1.RawValue
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm asn1.RawValue
+	Signature          []byte
+	UnsignedAttrs      []cmsAttribute `asn1:"optional,tag:1"`
+}
+
+type cmsAttribute struct {
+	Type   asn1.ObjectIdentifier
+	Values []asn1.RawValue `asn1:"set"`
+}
+
+// tstInfo is the RFC 3161 TSTInfo structure
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint asn1.RawValue
+	SerialNumber   asn1.RawValue
+	GenTime        time.Time     `asn1:"generalized"`
+	Accuracy       asn1.RawValue `asn1:"optional"`
+	Ordering       bool          `asn1:"optional,default:false"`
+	Nonce          asn1.RawValue `asn1:"optional"`
+	TSA            asn1.RawValue `asn1:"optional,explicit,tag:0"`
+	Extensions     asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+// timestampToken is what pdf-info reports about an RFC 3161 token
+type timestampToken struct {
+	GenTime   time.Time
+	Authority string
+}
+
+// parseSignedData decodes a CMS ContentInfo holding SignedData
+func parseSignedData(ber []byte) (*cmsSignedData, error) {
+	der, err := berToDER(ber)
+	if err != nil {
+		return nil, err
+	}
+	var ci cmsContentInfo
+	if _, err := asn1.Unmarshal(der, &ci); err != nil {
+		return nil, err
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, errors.New("not a CMS SignedData")
+	}
+	var sd cmsSignedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, err
+	}
+	return &sd, nil
+}
+
+// parseTimestampToken decodes an RFC 3161 timestamp token (a SignedData whose content is TSTInfo)
+func parseTimestampToken(der []byte) (*timestampToken, error) {
+	sd, err := parseSignedData(der)
+	if err != nil {
+		return nil, err
+	}
+	if !sd.EncapContentInfo.EContentType.Equal(oidTSTInfo) {
+		return nil, errors.New("not a timestamp token")
+	}
+	var octets []byte
+	if _, err := asn1.Unmarshal(sd.EncapContentInfo.EContent.Bytes, &octets); err != nil {
+		return nil, err
+	}
+	var info tstInfo
+	if _, err := asn1.Unmarshal(octets, &info); err != nil {
+		return nil, err
+	}
+	return &timestampToken{GenTime: info.GenTime, Authority: tsaName(info.TSA, cmsCertificates(sd))}, nil
+}
+
+// tsaName returns the TSA named in the token (GeneralName directoryName), or else the
+// subject of the token's time-stamping certificate
+func tsaName(tsa asn1.RawValue, certificates []*x509.Certificate) string {
+	// directoryName [4] EXPLICIT Name
+	if tsa.Class == asn1.ClassContextSpecific && tsa.Tag == 4 {
+		var rdn pkix.RDNSequence
+		if _, err := asn1.Unmarshal(tsa.Bytes, &rdn); err == nil {
+			var name pkix.Name
+			name.FillFromRDNSequence(&rdn)
+			if name.CommonName != "" {
+				return name.CommonName
+			}
+			return name.String()
+		}
+	}
+
+	for _, cert := range certificates {
+		for _, usage := range cert.ExtKeyUsage {
+			if usage == x509.ExtKeyUsageTimeStamping {
+				if cert.Subject.CommonName != "" {
+					return cert.Subject.CommonName
+				}
+				return cert.Subject.String()
+			}
+		}
+	}
+	return ""
+}
+
+// signatureTimestamp finds the signature-time-stamp unsigned attribute of a CMS signature
+// and decodes its token. It returns nil when the signature carries no timestamp.
+func signatureTimestamp(der []byte) (*timestampToken, error) {
+	sd, err := parseSignedData(der)
+	if err != nil {
+		return nil, err
+	}
+	for _, signer := range sd.SignerInfos {
+		for _, attr := range signer.UnsignedAttrs {
+			if attr.Type.Equal(oidSignatureTimeStamp) && len(attr.Values) > 0 {
+				return parseTimestampToken(attr.Values[0].FullBytes)
+			}
+		}
+	}
+	return nil, nil
+}
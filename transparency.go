@@ -0,0 +1,112 @@
+package main
+
+import (
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// hasTransparencyGroup reports whether a page or form XObject declares a transparency group
+// (/Group << /S /Transparency >>)
+func (pa *PDFAnalyzer) hasTransparencyGroup(ctx *model.Context, d types.Dict) bool {
+	groupObj, found := d.Find("Group")
+	if !found || groupObj == nil {
+		return false
+	}
+	group, err := ctx.DereferenceDict(groupObj)
+	if err != nil || group == nil {
+		return false
+	}
+	s := group.NameEntry("S")
+	return s != nil && *s == "Transparency"
+}
+
+// extGStateIsTransparent reports whether a graphics state parameter dictionary sets a soft
+// mask, a blend mode other than Normal or a constant alpha below 1
+func (pa *PDFAnalyzer) extGStateIsTransparent(ctx *model.Context, gs types.Dict) bool {
+	if smask, found := gs.Find("SMask"); found && smask != nil {
+		if name, ok := smask.(types.Name); !ok || name != "None" {
+			return true
+		}
+	}
+	if bmObj, found := gs.Find("BM"); found && bmObj != nil {
+		bm, err := ctx.Dereference(bmObj)
+		if err != nil {
+			return false
+		}
+		// /BM também pode ser uma lista, da qual o leitor usa o primeiro modo que conhece
+		if arr, ok := bm.(types.Array); ok && len(arr) > 0 {
+			bm = arr[0]
+		}
+		if name, ok := bm.(types.Name); ok && name != "Normal" && name != "Compatible" {
+			return true
+		}
+	}
+	for _, key := range []string{"CA", "ca"} {
+		if alphaObj, found := gs.Find(key); found && alphaObj != nil {
+			if alpha, err := ctx.DereferenceNumber(alphaObj); err == nil && alpha < 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resourcesUseTransparency reports whether a resources dictionary brings in transparency:
+// transparent graphics states, images with a soft mask, or form XObjects that are transparency
+// groups or use transparency themselves. visited holds the object numbers of the XObjects
+// already walked.
+func (pa *PDFAnalyzer) resourcesUseTransparency(ctx *model.Context, resources types.Dict, visited map[int]bool, depth int) bool {
+	for _, obj := range pa.resourceCategory(ctx, resources, "ExtGState") {
+		if gs, err := ctx.DereferenceDict(obj); err == nil && gs != nil && pa.extGStateIsTransparent(ctx, gs) {
+			return true
+		}
+	}
+
+	for _, obj := range pa.resourceCategory(ctx, resources, "XObject") {
+		if ref, ok := obj.(types.IndirectRef); ok {
+			if visited[int(ref.ObjectNumber)] {
+				continue
+			}
+			visited[int(ref.ObjectNumber)] = true
+		}
+		sd, _, err := ctx.DereferenceStreamDict(obj)
+		if err != nil || sd == nil {
+			continue
+		}
+		subtype := sd.Dict.NameEntry("Subtype")
+		if subtype == nil {
+			continue
+		}
+		switch *subtype {
+		case "Image":
+			if smask, found := sd.Dict.Find("SMask"); found && smask != nil {
+				return true
+			}
+		case "Form":
+			if pa.hasTransparencyGroup(ctx, sd.Dict) {
+				return true
+			}
+			if depth < maxResourceDepth && pa.resourcesUseTransparency(ctx, pa.resourcesDict(ctx, sd.Dict), visited, depth+1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pageHasTransparency reports whether a page uses transparency, which printers and PDF/X-1a
+// workflows have to flatten: a page transparency group, or transparency in its resources
+func (pa *PDFAnalyzer) pageHasTransparency(ctx *model.Context, pageNr int) bool {
+	pageDict, _, _, err := ctx.PageDict(pageNr, false)
+	if err != nil || pageDict == nil {
+		return false
+	}
+	if pa.hasTransparencyGroup(ctx, pageDict) {
+		return true
+	}
+	resources, err := pa.pageResources(ctx, pageNr)
+	if err != nil {
+		return false
+	}
+	return pa.resourcesUseTransparency(ctx, resources, make(map[int]bool), 0)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+)
+
+var (
+	startxrefPattern = regexp.MustCompile(`startxref\s+(\d+)`)
+	objStartPattern  = regexp.MustCompile(`\d+\s+\d+\s+obj\b`)
+)
+
+// detectTruncation looks for the signs of a file cut off mid-download: no final %%EOF, a
+// startxref offset beyond the end of the file, or a last object that never ends
+func detectTruncation(data []byte) []string {
+	if !isPDFData(data) {
+		return nil
+	}
+	var reasons []string
+
+	lastEOF := bytes.LastIndex(data, []byte("%%EOF"))
+	if lastEOF < 0 {
+		reasons = append(reasons, "missing %%EOF")
+	} else if tail := data[lastEOF:]; bytes.Contains(tail, []byte("endobj")) || objStartPattern.Match(tail) {
+		// Atualização incremental interrompida depois do último %%EOF
+		reasons = append(reasons, "incomplete update after the last %%EOF")
+	}
+
+	if all := startxrefPattern.FindAllSubmatch(data, -1); len(all) > 0 {
+		offset, err := strconv.ParseInt(string(all[len(all)-1][1]), 10, 64)
+		if err == nil && offset >= int64(len(data)) {
+			reasons = append(reasons, "xref beyond EOF")
+		}
+	} else {
+		reasons = append(reasons, "missing startxref")
+	}
+
+	if locs := objStartPattern.FindAllIndex(data, -1); len(locs) > 0 {
+		lastObj := locs[len(locs)-1][0]
+		if !bytes.Contains(data[lastObj:], []byte("endobj")) {
+			reasons = append(reasons, "last object incomplete")
+		}
+	}
+	return reasons
+}
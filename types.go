@@ -1,6 +1,7 @@
 package main
 
 import (
+	"crypto/x509"
 	"time"
 )
 
@@ -12,8 +13,7 @@ type PDFInfo struct {
 	FileSize     int64
 	FileSizeHuman string
 	LastModified time.Time
-	MD5Hash      string
-	SHA256Hash   string
+	Hashes       map[string]string // hex digests keyed by algorithm ("md5", "sha256", ...)
 
 	// Informações do documento PDF
 	Title        string
@@ -22,22 +22,93 @@ type PDFInfo struct {
 	Keywords     string
 	Creator      string
 	Producer     string
+	CreatorFamily  string // canonical program behind Creator (Word, LibreOffice, ...); empty when unknown
+	ProducerFamily string // canonical program behind Producer (iText, Ghostscript, ...); empty when unknown
 	CreationDate string
 	ModDate      string
+	CreationDateParsed time.Time // CreationDate as a time, with its UTC offset; zero when unparseable
+	ModDateParsed      time.Time // ModDate as a time, with its UTC offset; zero when unparseable
+	Trapped      string // Info /Trapped: "True", "False" or "Unknown"
+	CustomMetadata map[string]string // non-standard Info dictionary entries
+	XMPProperties  map[string]string // simple XMP properties keyed by "prefix:Name"
+	XMPPresent     bool              // the catalog has an XMP metadata stream
+	XMPPacket      string            // the raw XMP packet
+	XMPFields      []string          // document fields taken from XMP (empty in Info or in conflict with it)
+
+	HasNoMetadata bool // neither the Info dictionary nor XMP carries any standard field
+
+	DateTimezoneConsistent bool     // Info and XMP dates agree on timezone handling
+	DateTimezoneIssues     []string // dates without a timezone or with mismatched offsets
+	DisplayDocTitle bool   // ViewerPreferences /DisplayDocTitle: viewers show the title instead of the file name
+	DisplayTitle    string // what the reader/browser title bar shows for this document
 
 	// Informações técnicas
+	FallbackParsing bool // pdfcpu could not read the file; results come from raw byte scanning
+	IsTruncated       bool     // the file appears cut off (e.g. an interrupted download)
+	TruncationReasons []string // e.g. "missing %%EOF", "xref beyond EOF"
 	PDFVersion    string
+	HeaderVersion     string // version in the %PDF- header line
+	VersionOverridden bool   // the catalog /Version raises the header version (PDFVersion is the catalog's)
+	RevisionCount    int      // revisions (original save plus incremental updates)
+	RevisionVersions []string // PDF version in effect after each revision
+	Revisions        []RevisionInfo // each revision's place in the file and the signature that sealed it
+	DocumentID       string // first trailer /ID element, hex: identifies the document across saves
+	RevisionID       string // second trailer /ID element, hex: changes whenever the file is saved
+	IDChanged        bool   // the two /ID elements differ: the file was modified after it was created
 	PageCount     int
 	IsEncrypted   bool
 	IsLinearized  bool
+	LinearizationValid  bool     // the linearization parameter dictionary matches the file
+	LinearizationIssues []string // why the linearization is broken; empty when it is valid
 	IsTagged      bool
+	DeclaredLanguage string // catalog /Lang, e.g. "pt-BR"
+	LanguageMissing  bool   // tagged document without /Lang: an accessibility gap
+	HasStructTree       bool     // the catalog has a /StructTreeRoot
+	TaggingInconsistent bool     // marked as tagged without a structure tree, or the other way around
+	PDFUAConformance    string   // e.g. "PDF/UA-1", or "PDF/UA-1 claimed, non-conformant"; empty when not claimed
+	AccessibilityIssues []string // structural accessibility gaps; empty when none were found
 	HasBookmarks  bool
 	HasAttachments bool
 	HasForms      bool
 	HasJavaScript bool
-	HasAnnotations bool
+	JavaScriptSnippets []string // "location: start of the script", e.g. "OpenAction: app.alert(...)"
+	SuspiciousActions  []ActionInfo // actions that launch programs, open URLs or remote files, or submit data
+	HasOpenAction      bool         // an action other than going to a page runs when the document is opened
+	RiskScore          int          // 0-100 malware triage score, the sum of the RiskFactors weights
+	RiskFactors        []string     // the signals behind RiskScore, each with its weight
+	HasAnnotations bool // annotations other than form field widgets
+	HasMarkupAnnotations  bool // notes, highlights, stamps and other markup annotations
+	WidgetAnnotationCount int  // form field widgets, counted apart from the annotations
+	HasLayers      bool
+	HasTransparency bool // some page uses transparency groups, soft masks, blend modes or constant alpha
+	Layers         []LayerInfo // optional content groups (layers), in /OCGs order
+	HiddenLayers   []string // layers that are OFF when the document is opened
+	RoleMap                map[string]string // structure tree /RoleMap: custom type -> standard type
+	UnmappedStructureTypes []string          // custom structure types used without a standard mapping
+	TaggedContentOps           int  // painting operations inside marked content referenced by the structure tree
+	ArtifactContentOps         int  // painting operations marked as /Artifact
+	UntaggedContentOps         int  // painting operations outside any marked content
+	OrphanedContentOps         int  // painting operations in marked content the structure tree does not reference
+	SubstantialUntaggedContent bool // tagged document with a significant share of untagged content
+	DanglingReferenceCount    int      // distinct referenced objects that do not exist
+	DanglingReferenceExamples []string // a few of those references, for diagnosis
+	AnnotationsWithoutAppearance int // visible annotations lacking an /AP appearance stream
+	FilterChains        map[string]int // stream filter chains ("ASCII85Decode > FlateDecode") and how many streams use each
+	UnusualFilterChains []string       // deeply nested, repeated or non-standard chains, with the reason
+	HighEntropyStreams    []StreamEntropy // near-random decoded content (with ComputeEntropy)
+	HighestEntropyStreams []StreamEntropy // the few streams with the highest entropy (with ComputeEntropy)
+	OptimizationSavings  int64    // estimated bytes an optimizer could save
+	OptimizationFindings []string // what the savings would come from
 
 	// Informações de segurança
+	SecurityHandler     string // encrypt dictionary /Filter, with /SubFilter in parentheses
+	SecurityHandlerKind string // e.g. "password-based", "Microsoft IRM (rights management)", "custom"
+	IsRightsManaged     bool   // rights-management server (Microsoft IRM, Adobe policy server), not a password
+	EncryptionAlgorithm string // "RC4", "AES", ...; empty when not recognized
+	EncryptionKeyBits   int    // key length in bits
+	EncryptionRevision  int    // standard security handler revision (/R)
+	PermissionsEnforced bool     // the permission flags are bound to the encryption key (R5+ /Perms), not merely advisory
+	SecurityWarnings    []string // deprecated or weak protection (RC4, 40-bit keys, bypassable restrictions)
 	UserPasswordSet  bool
 	OwnerPasswordSet bool
 	PrintAllowed     bool
@@ -52,30 +123,119 @@ type PDFInfo struct {
 	// Informações de assinatura digital
 	HasDigitalSignatures bool
 	SignatureCount       int
+	DocumentTimestampCount int // document timestamps (DTS), not counted as signatures
+	SignatureCountEstimated bool // counted from byte patterns because the file could not be parsed
 	Signatures          []DigitalSignatureInfo
+	SignatureOrderConsistent bool     // each signature covers the ones applied before it
+	SignatureOrderIssues     []string // signatures whose byte ranges don't nest as expected
 
 	// Informações das páginas
 	Pages []PageInfo
 
+	// Informações de formulário
+	FormDefaultAppearance  string   // AcroForm /DA
+	FormDefaultFonts       []string // fonts in AcroForm /DR /Font, as "Name (BaseFont)"
+	FormDefaultFontMissing bool     // /DA selects a font that /DR does not define
+	FilledFieldCount       int      // fields with a non-empty value (push buttons excluded)
+	EmptyFieldCount        int      // fields without a value
+	FormFields             []FormFieldInfo // terminal fields of the AcroForm, in /Fields order
+	HasXFA                 bool     // AcroForm /XFA: the form is defined by XFA (XML Forms Architecture)
+	XFAPacketLength        int      // decoded bytes of the XFA packets
+	XFATemplateName        string   // name of the top-level subform of the XFA template
+
 	// Informações de conteúdo
 	TotalTextLength int
-	FontsUsed       []string
+	TotalWordCount  int // words in the extracted text (each Chinese/Japanese character counts as one)
+	TotalLineCount  int // non-blank lines in the extracted text
+	DetectedLanguage   string  // ISO 639-1 code guessed from the extracted text; empty when unknown
+	LanguageConfidence float64 // 0.5 (tie with the runner-up) to 1
+	LanguageMismatch   bool    // the declared and detected languages differ
+	ContentStreamTextLength   int  // glyphs shown by text operators (with CrossCheckText)
+	TextExtractionDiscrepancy bool // the two text lengths disagree widely
+	FontsUsed       []FontInfo
+	AllFontsEmbedded bool // every font used comes with the file
 	ImagesCount     int
+	ColorPageCount      int // pages that would print in color
+	BlackWhitePageCount int // pages that would print in black ink only
+	PrintIntended       bool // has a PDF/X output intent (/OutputIntents /S /GTS_PDFX)
+	ColorSpaces         []string // color spaces used by the pages, e.g. "DeviceCMYK", "Separation (PANTONE 185 C)"
+	SpotColors          []string // Separation/DeviceN colorants other than the process inks
+	IsProbablyScanned   bool    // image-only pages with no text layer: the text needs OCR
+	ImagesPerPage       float64 // images per page, the ratio behind IsProbablyScanned
+	DocumentClass       string // best guess, e.g. "single-page A4 form", "slide deck in landscape 16:9"
 	
 	// Informações extras
 	Bookmarks    []BookmarkInfo
 	Attachments  []AttachmentInfo
+	AttachmentsExtracted int // files written with ExtractAttachmentsDir
+	EmbeddedPDFs []EmbeddedPDFInfo // with RecurseEmbedded
 	Annotations  []AnnotationInfo
+
+	// Quais análises rodaram por completo
+	Completeness AnalysisCompleteness
 }
 
 // PageInfo holds information about a specific page
+// LayerInfo is an optional content group (layer)
+type LayerInfo struct {
+	Name    string
+	Visible bool // ON in the default configuration (/D), i.e. shown when the document is opened
+}
+
+// FontInfo is a font used by the pages
+type FontInfo struct {
+	Name     string // base font without the subset prefix, with its type, e.g. "Helvetica (Type1)"
+	Embedded bool   // the font program is in the file; otherwise the viewer substitutes a system font
+}
+
+// ActionInfo is an action that reaches outside the document
+type ActionInfo struct {
+	Type     string // Launch, URI, SubmitForm, ImportData, GoToR or GoToE
+	Location string // where it is triggered, e.g. "OpenAction" or "Page 1 annotation"
+	Target   string // the URI, or the launched, opened or submitted-to file
+}
+
+// FormFieldInfo is a terminal form field with its current value
+type FormFieldInfo struct {
+	Name     string // fully qualified name, e.g. "applicant.name"
+	Type     string // /FT: Tx, Btn, Ch or Sig
+	Value    string // /V as text, shortened; empty when the field has no value
+	Required bool   // /Ff required flag
+	ReadOnly bool   // /Ff read-only flag
+}
+
+// RevisionInfo is one revision of the document: the original save or an incremental update
+type RevisionInfo struct {
+	Number         int    // 1-based, in file order
+	Offset         int64  // where the revision starts in the file
+	Size           int64  // bytes up to and including its %%EOF
+	Version        string // PDF version in effect after the revision
+	SignedBy       string // signer whose signature was applied in this revision; empty when none
+	SignatureField string
+}
+
 type PageInfo struct {
 	Number     int
 	Width      float64
 	Height     float64
 	Rotation   int
 	TextLength int
+	WordCount  int
+	LineCount  int
 	ImageCount int
+	EffectiveWidth  float64 // size a viewer shows: the CropBox, turned by the rotation
+	EffectiveHeight float64
+	PaperSize  string // named paper size with orientation, e.g. "A4 portrait", or "Custom"
+	MediaBox   *PageBox // page boundaries; nil when absent
+	CropBox    *PageBox // visible area, MediaBox when absent
+	BleedBox   *PageBox
+	TrimBox    *PageBox // finished size after trimming
+	ArtBox     *PageBox
+	BoxIssues  []string // e.g. "CropBox extends beyond MediaBox"
+	IsColor    bool   // would print in color
+	ColorSpaces []string // color spaces used by the page
+	HasTransparency bool // the page or its XObjects use transparency
+	Text       string // extracted plain text; only kept with KeepText (--dump-text, --text-out)
 }
 
 // BookmarkInfo holds information about a bookmark
@@ -87,9 +247,11 @@ type BookmarkInfo struct {
 
 // AttachmentInfo holds information about an attachment
 type AttachmentInfo struct {
-	Name string
-	Size int64
-	Type string
+	Name       string
+	Size       int64
+	Type       string
+	Category   string // e.g. "executable", "script", "archive"; empty for ordinary files
+	Dangerous  bool   // runs code when opened: an executable, a script or a macro-enabled document
 }
 
 // AnnotationInfo holds information about an annotation
@@ -99,6 +261,21 @@ type AnnotationInfo struct {
 	Content string
 }
 
+// EmbeddedPDFInfo holds the analysis of a PDF embedded as an attachment
+type EmbeddedPDFInfo struct {
+	Name       string
+	Info       *PDFInfo // nil when the embedded PDF was skipped
+	SkipReason string   // why the embedded PDF was not analyzed
+}
+
+// URLInfo holds information about a URL found in the document
+type URLInfo struct {
+	URL         string
+	Sources     []string // where the URL was found, e.g. "link annotation (page 2)", "JavaScript"
+	DisplayText string   // text shown over a link annotation, if any
+	Mismatch    bool     // the shown text names a different host than the target
+}
+
 // DigitalSignatureInfo holds information about a digital signature
 type DigitalSignatureInfo struct {
 	Type          string
@@ -111,16 +288,90 @@ type DigitalSignatureInfo struct {
 	FieldName     string
 	IsValid       bool
 	IsCertified   bool
+	IsDocumentTimestamp bool // PAdES document timestamp (SubFilter ETSI.RFC3161): no signer, only a TSA token
 	Status        string
 	ValidationErrors []string
 	
+	// Signer certificate, from the CMS signature in /Contents
+	SignerCN         string
+	SignerOrg        string
+	IssuerCN         string
+	CertSerialNumber string // hexadecimal
+	CertNotBefore    string
+	CertNotAfter     string
+	SignerCPF        string // ICP-Brasil: CPF of the holder (or of the legal entity's responsible person)
+	SignerCNPJ       string // ICP-Brasil: CNPJ of the legal entity
+	ChainTrusted     bool   // the certificate chains to a trusted root (--ca-bundle or system roots)
+	ChainError       string // why the chain is not trusted
+
 	// Timestamp information
 	HasTimestamp     bool
 	TimestampType    string
 	TimestampTime    string
 	TimestampAuthority string
 	TimestampStatus  string
+
+	// Trusted time check (--trusted-time)
+	SignedBeforeTrustedTime string // "Yes", "No" or "Unknown"; empty when no trusted time was given
+	TrustedTimeSource       string // which time the check used: "timestamp token" or "signing time"
+
+	// Signing history
+	Sequence int // position in signing order, counted separately for signatures and document timestamps
+	Revision int // revision (incremental update) that applied the signature; 0 when unknown
+
+	// Incremental updates after signing
+	ModifiedAfterSigning       bool  // incremental updates or changed objects were written after the signed byte range
+	IncrementalUpdatesAfterSig int   // incremental update sections appended after the signed byte range
+	SignedRangeEnd            int64  // end of the signed byte range; 0 when it could not be determined
+	ObjectsModifiedAfter      []int  // object numbers added or changed after the signed byte range
+	ModificationsAfterSigning int    // objects and xref sections written after the signed byte range
+	ModificationSummary       string // e.g. "2 annotations, 1 page content, 1 xref"
+
+	// Signed byte range coverage
+	CoversWholeDocument bool   // the byte range covers the whole file except the signature's /Contents
+	BytesAfterSignature int64  // bytes past the end of the signed byte range, i.e. not covered by this signature
+	ByteRangeDigest     string // "match" or "mismatch" against the digest in the signature; empty when it could not be checked
+
+	// Visible appearance
+	AppearancePage         int    // page of the visible signature widget; 0 when invisible
+	AppearanceOverlapsText bool   // the widget rectangle covers page text
+	OverlappedText         string // a snippet of the covered text
 }
 
 // PDFAnalyzer is the main analyzer struct
-type PDFAnalyzer struct{}
+type PDFAnalyzer struct {
+	// ExtractAttachmentsDir, when set, is where embedded files are saved
+	ExtractAttachmentsDir string
+
+	// PageRange selects the pages listed in the report; the zero value lists the first few
+	PageRange pageRange
+
+	// HashAlgorithms are the file digests to compute; nil means MD5 and SHA256, empty means none
+	HashAlgorithms []string
+
+	// TrustRoots are the CA certificates signer certificates must chain to; nil uses the system roots
+	TrustRoots *x509.CertPool
+
+	// Password opens encrypted documents (tried as both user and owner password)
+	Password string
+
+	// TrustedTime, when set, is the reference instant each signature is checked against
+	TrustedTime time.Time
+
+	// Quiet leaves the decoration (banners, rules, emoji, footer) out of the text report
+	Quiet bool
+
+	// KeepText keeps the extracted text of each page (PageInfo.Text) instead of only its length
+	KeepText bool
+
+	// CrossCheckText also counts text in the content streams and compares it with the extracted text
+	CrossCheckText bool
+
+	// ComputeEntropy computes the Shannon entropy of each stream, flagging near-random content
+	ComputeEntropy bool
+
+	// RecurseEmbedded also analyzes PDFs embedded as attachments
+	RecurseEmbedded bool
+	embeddedDepth   int             // nesting level of the file being analyzed
+	ancestorPDFs    map[string]bool // SHA256 of the PDFs that embed the file being analyzed
+}
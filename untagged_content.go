@@ -0,0 +1,118 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// mcidPattern finds the MCID in an inline BDC property list
+var mcidPattern = regexp.MustCompile(`/MCID\s+(\d+)`)
+
+// A tagged document is flagged when more than untaggedContentShare of its painting operations
+// are untagged, and at least untaggedContentMinOps of them
+const (
+	untaggedContentShare  = 0.10
+	untaggedContentMinOps = 5
+)
+
+// contentPaintingOps are the operators that put visible content on the page
+var contentPaintingOps = map[string]bool{
+	"Tj": true, "TJ": true, "'": true, "\"": true,
+	"f": true, "F": true, "f*": true, "B": true, "B*": true, "b": true, "b*": true, "S": true, "s": true,
+	"Do": true, "sh": true, "BI": true,
+}
+
+// Marked-content states of a painting operation
+const (
+	markedUntagged = iota // outside any tagged or artifact marked content
+	markedTagged          // inside marked content whose MCID the structure tree references
+	markedOrphan          // inside marked content with an MCID the structure tree does not reference
+	markedArtifact        // inside /Artifact marked content
+)
+
+// analyzeUntaggedContent classifies the painting operations of every page as tagged, artifact
+// or untagged by following the BDC/BMC/EMC nesting and matching MCIDs against the structure tree
+func (pa *PDFAnalyzer) analyzeUntaggedContent(ctx *model.Context, info *PDFInfo, mcids structureMCIDs) {
+	for i := 1; i <= ctx.PageCount; i++ {
+		pageDict, pageRef, inherited, err := ctx.PageDict(i, true)
+		if err != nil || pageDict == nil {
+			continue
+		}
+		content, err := ctx.PageContent(pageDict, i)
+		if err != nil {
+			continue
+		}
+		resources := pa.pageDictResources(ctx, pageDict, inherited)
+		var pageMCIDs map[int]bool
+		if pageRef != nil {
+			pageMCIDs = mcids[int(pageRef.ObjectNumber)]
+		}
+
+		stack := []int{markedUntagged}
+		for _, op := range parseContentOps(content) {
+			current := stack[len(stack)-1]
+			switch op.Operator {
+			case "BMC", "BDC":
+				state := current
+				if len(op.Operands) > 0 && op.Operands[0] == "/Artifact" {
+					state = markedArtifact
+				} else if op.Operator == "BDC" && len(op.Operands) > 1 && current != markedArtifact {
+					if mcid, ok := pa.markedContentID(ctx, resources, op.Operands[1]); ok {
+						state = markedOrphan
+						if pageMCIDs[mcid] {
+							state = markedTagged
+						}
+					}
+				}
+				stack = append(stack, state)
+			case "EMC":
+				if len(stack) > 1 {
+					stack = stack[:len(stack)-1]
+				}
+			default:
+				if !contentPaintingOps[op.Operator] {
+					continue
+				}
+				switch current {
+				case markedTagged:
+					info.TaggedContentOps++
+				case markedArtifact:
+					info.ArtifactContentOps++
+				case markedOrphan:
+					info.OrphanedContentOps++
+				default:
+					info.UntaggedContentOps++
+				}
+			}
+		}
+	}
+
+	total := info.TaggedContentOps + info.ArtifactContentOps + info.OrphanedContentOps + info.UntaggedContentOps
+	missing := info.UntaggedContentOps + info.OrphanedContentOps
+	info.SubstantialUntaggedContent = total > 0 && missing >= untaggedContentMinOps &&
+		float64(missing)/float64(total) > untaggedContentShare
+}
+
+// markedContentID returns the MCID of a BDC property list, given inline or as a /Properties resource name
+func (pa *PDFAnalyzer) markedContentID(ctx *model.Context, resources types.Dict, operand string) (int, bool) {
+	if strings.HasPrefix(operand, "/") {
+		props, err := ctx.DereferenceDict(pa.namedResource(ctx, resources, "Properties", operand))
+		if err != nil || props == nil {
+			return 0, false
+		}
+		if mcid := props.IntEntry("MCID"); mcid != nil {
+			return *mcid, true
+		}
+		return 0, false
+	}
+	m := mcidPattern.FindStringSubmatch(operand)
+	if m == nil {
+		return 0, false
+	}
+	mcid, err := strconv.Atoi(m[1])
+	return mcid, err == nil
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+var (
+	urlPattern       = regexp.MustCompile(`(?i)\b(?:https?|ftp)://[^\s"'<>()\\{}]+`)
+	hostPattern      = regexp.MustCompile(`(?i)\b(?:https?://)?((?:[a-z0-9-]+\.)+[a-z]{2,})\b`)
+	xmlnsAttrPattern = regexp.MustCompile(`xmlns(?::[\w.-]+)?\s*=\s*("[^"]*"|'[^']*')`)
+	urlTrailingTrim  = ".,;:!?"
+)
+
+// ExtractURLs collects every URL in the document from link annotations, URI actions,
+// JavaScript and XMP metadata, deduplicated and sorted
+func (pa *PDFAnalyzer) ExtractURLs(filePath string) ([]URLInfo, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading PDF: %v", err)
+	}
+	ctx, err := pa.readContext(data)
+	if err != nil {
+		return nil, fmt.Errorf("error reading PDF: %v", err)
+	}
+
+	found := make(map[string]*URLInfo)
+	add := func(rawURL, source string) *URLInfo {
+		rawURL = strings.TrimSpace(rawURL)
+		if rawURL == "" {
+			return nil
+		}
+		u, ok := found[rawURL]
+		if !ok {
+			u = &URLInfo{URL: rawURL}
+			found[rawURL] = u
+		}
+		for _, s := range u.Sources {
+			if s == source {
+				return u
+			}
+		}
+		u.Sources = append(u.Sources, source)
+		return u
+	}
+
+	// Texto posicionado, para comparar o texto exibido com o destino do link
+	var textReader *pdf.Reader
+	if r, err := pa.textReader(data); err == nil {
+		textReader = r
+	}
+
+	// Link annotations
+	for i := 1; i <= ctx.PageCount; i++ {
+		pageDict, _, _, err := ctx.PageDict(i, false)
+		if err != nil || pageDict == nil {
+			continue
+		}
+		for _, annot := range pa.pageAnnotations(ctx, pageDict) {
+			if subtype := annot.NameEntry("Subtype"); subtype == nil || *subtype != "Link" {
+				continue
+			}
+			target := pa.uriActionTarget(ctx, annot)
+			if target == "" {
+				continue
+			}
+			u := add(target, fmt.Sprintf("link annotation (page %d)", i))
+			if textReader == nil || u.DisplayText != "" {
+				continue
+			}
+			if rect := pa.rectEntry(ctx, annot, "Rect"); rect != nil {
+				u.DisplayText = pageTextInRect(textReader, i, rect)
+				u.Mismatch = displayTextMismatch(u.DisplayText, target)
+			}
+		}
+	}
+
+	// URI actions and JavaScript anywhere in the document
+	pa.forEachDict(ctx, func(objNr int, d types.Dict) {
+		if s := d.NameEntry("S"); s != nil && *s == "URI" {
+			if target := getStringFromDict(d, "URI"); target != "" {
+				if _, known := found[strings.TrimSpace(target)]; !known {
+					add(target, "URI action")
+				}
+			}
+		}
+		if js, ok := d.Find("JS"); ok && js != nil {
+			for _, match := range findURLs(pa.stringOrStreamText(ctx, js)) {
+				add(match, "JavaScript")
+			}
+		}
+	})
+
+	// XMP metadata, ignoring namespace declarations
+	packet := xmlnsAttrPattern.ReplaceAllString(pa.readXMPPacket(ctx), "")
+	for _, match := range findURLs(packet) {
+		if _, isNamespace := knownXMPNamespaces[match]; !isNamespace {
+			add(match, "XMP metadata")
+		}
+	}
+
+	urls := make([]URLInfo, 0, len(found))
+	for _, u := range found {
+		urls = append(urls, *u)
+	}
+	sort.Slice(urls, func(i, j int) bool { return urls[i].URL < urls[j].URL })
+	return urls, nil
+}
+
+// uriActionTarget returns the URI of an annotation's /A action, if it is a URI action
+func (pa *PDFAnalyzer) uriActionTarget(ctx *model.Context, annot types.Dict) string {
+	actionObj, found := annot.Find("A")
+	if !found || actionObj == nil {
+		return ""
+	}
+	action, err := ctx.DereferenceDict(actionObj)
+	if err != nil || action == nil {
+		return ""
+	}
+	if s := action.NameEntry("S"); s == nil || *s != "URI" {
+		return ""
+	}
+	return getStringFromDict(action, "URI")
+}
+
+// rectEntry reads a rectangle entry such as /Rect or /MediaBox as [llx lly urx ury]
+func (pa *PDFAnalyzer) rectEntry(ctx *model.Context, d types.Dict, key string) []float64 {
+	obj, found := d.Find(key)
+	if !found || obj == nil {
+		return nil
+	}
+	arr, err := ctx.DereferenceArray(obj)
+	if err != nil || len(arr) < 4 {
+		return nil
+	}
+	rect := make([]float64, 4)
+	for i := 0; i < 4; i++ {
+		n, err := ctx.DereferenceNumber(arr[i])
+		if err != nil {
+			return nil
+		}
+		rect[i] = n
+	}
+	return rect
+}
+
+// pageTextInRect returns the text drawn inside a rectangle of a page
+func pageTextInRect(r *pdf.Reader, pageNr int, rect []float64) (text string) {
+	defer func() {
+		// A extração de texto posicionado pode entrar em pânico em conteúdos malformados
+		if recover() != nil {
+			text = ""
+		}
+	}()
+
+	page := r.Page(pageNr)
+	if page.V.IsNull() {
+		return ""
+	}
+	llx, lly, urx, ury := rect[0], rect[1], rect[2], rect[3]
+	if llx > urx {
+		llx, urx = urx, llx
+	}
+	if lly > ury {
+		lly, ury = ury, lly
+	}
+
+	var b strings.Builder
+	for _, t := range page.Content().Text {
+		cx := t.X + t.W/2
+		if cx >= llx && cx <= urx && t.Y >= lly-1 && t.Y <= ury+1 {
+			b.WriteString(t.S)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// findURLs returns the http(s)/ftp URLs in a piece of text
+func findURLs(text string) []string {
+	var urls []string
+	for _, match := range urlPattern.FindAllString(text, -1) {
+		if trimmed := strings.TrimRight(match, urlTrailingTrim); trimmed != "" {
+			urls = append(urls, trimmed)
+		}
+	}
+	return urls
+}
+
+// displayTextMismatch reports whether a link's visible text names a different host than
+// its actual target, a common phishing technique
+func displayTextMismatch(displayText, target string) bool {
+	m := hostPattern.FindStringSubmatch(displayText)
+	if m == nil {
+		return false
+	}
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Hostname() == "" {
+		return false
+	}
+	shown := strings.TrimPrefix(strings.ToLower(m[1]), "www.")
+	actual := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	return shown != actual && !strings.HasSuffix(actual, "."+shown)
+}
+
+// printURLList writes the URL list to w, one URL per line followed by where it was found
+func printURLList(w io.Writer, urls []URLInfo, asJSON bool) error {
+	if asJSON {
+		data, err := json.MarshalIndent(urls, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+
+	ew := &errWriter{w: w}
+	for _, u := range urls {
+		fmt.Fprintf(ew, "%s\t[%s]\n", u.URL, strings.Join(u.Sources, ", "))
+		if u.Mismatch {
+			fmt.Fprintf(ew, "\tWarning: link text %q does not match the target\n", u.DisplayText)
+		}
+	}
+	return ew.err
+}
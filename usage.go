@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// usageExamples is shown after the options in the --help output
+const usageExamples = `Examples:
+  pdf-info document.pdf                      full report
+  pdf-info --json document.pdf               report as JSON
+  pdf-info -o out.json --json doc.pdf        JSON written to out.json
+  pdf-info --password secret locked.pdf      analyze an encrypted PDF
+  pdf-info --pages 10-20 book.pdf            list pages 10 to 20 in the report
+  pdf-info --recursive --csv scans/ > a.csv  one CSV row per PDF under scans/
+  pdf-info --summary scans/                  one aligned line per PDF in scans/
+  pdf-info --silent --assert 'is_encrypted==false' document.pdf
+  pdf-info --dump-text report.pdf > report.txt
+  pdf-info --quiet document.pdf | grep '^Producer:'
+  pdf-info --verify-only --ca-bundle icp-brasil.pem signed.pdf
+`
+
+// printUsage prints the --help output: usage line, options, examples and exit statuses
+func printUsage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, "Usage: pdf-info [options] <file.pdf|directory>...")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Analyzes PDF files and prints metadata, structure, security, content and signature details.")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Options:")
+	flag.PrintDefaults()
+	fmt.Fprintln(out)
+	fmt.Fprint(out, usageExamples)
+	fmt.Fprintln(out)
+	fmt.Fprint(out, exitCodesHelp)
+}
+
+// parseCommandLine parses the options wherever they appear among the file arguments
+// (flag.Parse alone stops at the first file) and returns the files. Everything after "--" is a
+// file.
+func parseCommandLine(fs *flag.FlagSet, args []string) []string {
+	var files []string
+	for {
+		// Com ExitOnError, Parse encerra o programa em caso de erro ou -h
+		fs.Parse(args)
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(files, rest...)
+		}
+		if len(rest) == 0 {
+			return files
+		}
+		files = append(files, rest[0])
+		args = rest[1:]
+	}
+}
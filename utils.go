@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
 )
@@ -10,6 +11,10 @@ import (
 func getStringFromDict(dict types.Dict, key string) string {
 	if obj, found := dict.Find(key); found && obj != nil {
 		if str, ok := obj.(types.StringLiteral); ok {
+			// Decodificar escapes e UTF-16, mantendo o valor bruto em caso de erro
+			if decoded, err := types.StringLiteralToString(str); err == nil {
+				return decoded
+			}
 			return str.Value()
 		}
 		if name, ok := obj.(types.Name); ok {
@@ -17,6 +22,9 @@ func getStringFromDict(dict types.Dict, key string) string {
 		}
 		// Também tentar como HexLiteral
 		if hex, ok := obj.(types.HexLiteral); ok {
+			if decoded, err := types.HexLiteralToString(hex); err == nil {
+				return decoded
+			}
 			return hex.Value()
 		}
 	}
@@ -46,8 +54,8 @@ func boolToYesNo(b bool) string {
 }
 
 // printIfNotEmpty prints label: value only if value is not empty
-func printIfNotEmpty(label, value string) {
+func printIfNotEmpty(w io.Writer, label, value string) {
 	if value != "" {
-		fmt.Printf("%s: %s\n", label, value)
+		fmt.Fprintf(w, "%s: %s\n", label, value)
 	}
 }
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+var versionPattern = regexp.MustCompile(`^(\d)\.(\d)$`)
+
+// pdfVersionString formats a version as read by pdfcpu. model.Version is an enumeration
+// (V10 ... V17, V20), so its value is the minor version for 1.x headers but not for 2.0.
+func pdfVersionString(v model.Version) string {
+	if v == model.V20 {
+		return "2.0"
+	}
+	return fmt.Sprintf("1.%d", int(v))
+}
+
+// compareVersions compares two "major.minor" versions, returning -1, 0 or 1. Versions that
+// don't parse sort lowest.
+func compareVersions(a, b string) int {
+	am, an, aok := splitVersion(a)
+	bm, bn, bok := splitVersion(b)
+	switch {
+	case !aok && !bok:
+		return 0
+	case !aok:
+		return -1
+	case !bok:
+		return 1
+	case am != bm:
+		return compareInts(am, bm)
+	}
+	return compareInts(an, bn)
+}
+
+// splitVersion parses "major.minor"
+func splitVersion(v string) (major, minor int, ok bool) {
+	m := versionPattern.FindStringSubmatch(strings.TrimSpace(v))
+	if m == nil {
+		return 0, 0, false
+	}
+	major, _ = strconv.Atoi(m[1])
+	minor, _ = strconv.Atoi(m[2])
+	return major, minor, true
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// catalogVersion returns the /Version entry of the document catalog (e.g. "1.7"), which an
+// incremental update can use to raise the version given in the header, or ""
+func catalogVersion(ctx *model.Context) string {
+	if ctx == nil || ctx.RootDict == nil {
+		return ""
+	}
+	name := ctx.RootDict.NameEntry("Version")
+	if name == nil || !versionPattern.MatchString(*name) {
+		return ""
+	}
+	return *name
+}
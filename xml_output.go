@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PrintXML writes the analysis result to w as an indented XML document. Like the JSON output it
+// takes the element names from the PDFInfo field names; lists become a collection element
+// holding one element per item (<Pages><Page>...</Page></Pages>) and maps a collection of
+// <Entry key="...">.
+func (pa *PDFAnalyzer) PrintXML(w io.Writer, info *PDFInfo) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := encodeXMLValue(enc, xml.StartElement{Name: xml.Name{Local: "PDFInfo"}}, reflect.ValueOf(info)); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// xmlItemName names the elements of a list: the item type without its "Info" suffix
+// (PageInfo gives Page), or Item for lists of strings and numbers
+func xmlItemName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct {
+		if name := strings.TrimSuffix(t.Name(), "Info"); name != "" {
+			return name
+		}
+	}
+	return "Item"
+}
+
+// encodeXMLValue writes v as the element start: structs field by field, lists and maps as
+// collections, and anything else as escaped text. Nil pointers are left out.
+func encodeXMLValue(enc *xml.Encoder, start xml.StartElement, v reflect.Value) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	// time.Time e afins já sabem se escrever como texto
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		if err != nil {
+			return err
+		}
+		return encodeXMLText(enc, start, string(text))
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			if err := encodeXMLValue(enc, xml.StartElement{Name: xml.Name{Local: t.Field(i).Name}}, v.Field(i)); err != nil {
+				return err
+			}
+		}
+		return enc.EncodeToken(start.End())
+
+	case reflect.Slice, reflect.Array:
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		item := xml.StartElement{Name: xml.Name{Local: xmlItemName(v.Type().Elem())}}
+		for i := 0; i < v.Len(); i++ {
+			if err := encodeXMLValue(enc, item, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return enc.EncodeToken(start.End())
+
+	case reflect.Map:
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		keys := make([]string, 0, v.Len())
+		values := make(map[string]reflect.Value, v.Len())
+		for _, k := range v.MapKeys() {
+			key := k.String()
+			keys = append(keys, key)
+			values[key] = v.MapIndex(k)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			entry := xml.StartElement{
+				Name: xml.Name{Local: "Entry"},
+				Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: key}},
+			}
+			if err := encodeXMLValue(enc, entry, values[key]); err != nil {
+				return err
+			}
+		}
+		return enc.EncodeToken(start.End())
+
+	case reflect.Bool:
+		return encodeXMLText(enc, start, strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return encodeXMLText(enc, start, strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return encodeXMLText(enc, start, strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		return encodeXMLText(enc, start, strconv.FormatFloat(v.Float(), 'f', -1, 64))
+	}
+	return encodeXMLText(enc, start, v.String())
+}
+
+// encodeXMLText writes an element holding text; the encoder escapes markup characters and
+// replaces characters XML cannot carry
+func encodeXMLText(enc *xml.Encoder, start xml.StartElement, text string) error {
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := enc.EncodeToken(xml.CharData(text)); err != nil {
+		return err
+	}
+	return enc.EncodeToken(start.End())
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// Namespace prefixes used when the XMP packet does not declare its own
+var knownXMPNamespaces = map[string]string{
+	"http://purl.org/dc/elements/1.1/":            "dc",
+	"http://ns.adobe.com/xap/1.0/":                "xmp",
+	"http://ns.adobe.com/pdf/1.3/":                "pdf",
+	"http://ns.adobe.com/xap/1.0/mm/":             "xmpMM",
+	"http://ns.adobe.com/xap/1.0/rights/":         "xmpRights",
+	"http://www.aiim.org/pdfa/ns/id/":             "pdfaid",
+	"http://www.aiim.org/pdfua/ns/id/":            "pdfuaid",
+	"http://ns.adobe.com/photoshop/1.0/":          "photoshop",
+	"http://www.w3.org/1999/02/22-rdf-syntax-ns#": "rdf",
+}
+
+const rdfNamespace = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+
+// readXMPPacket returns the raw XMP packet referenced by the catalog /Metadata entry
+func (pa *PDFAnalyzer) readXMPPacket(ctx *model.Context) string {
+	if ctx == nil || ctx.RootDict == nil {
+		return ""
+	}
+	obj, found := ctx.RootDict.Find("Metadata")
+	if !found || obj == nil {
+		return ""
+	}
+	sd, _, err := ctx.DereferenceStreamDict(obj)
+	if err != nil || sd == nil {
+		return ""
+	}
+	if err := sd.Decode(); err != nil {
+		return ""
+	}
+	return string(sd.Content)
+}
+
+// extractXMPMetadata reads the XMP packet and fills the document fields from it: fields the
+// Info dictionary left empty, and text fields where XMP disagrees with Info, since XMP is the
+// authoritative source in current PDF versions. Conflicting dates keep the Info value; the
+// timezone check reports them instead.
+func (pa *PDFAnalyzer) extractXMPMetadata(ctx *model.Context, info *PDFInfo) {
+	packet := pa.readXMPPacket(ctx)
+	if packet == "" {
+		return
+	}
+	info.XMPPresent = true
+	info.XMPPacket = packet
+	info.XMPProperties = parseXMPProperties(packet)
+
+	fields := []struct {
+		prop   string
+		name   string
+		target *string
+	}{
+		{"dc:title", "Title", &info.Title},
+		{"dc:creator", "Author", &info.Author},
+		{"dc:description", "Subject", &info.Subject},
+		{"pdf:Keywords", "Keywords", &info.Keywords},
+		{"xmp:CreatorTool", "Creator", &info.Creator},
+		{"pdf:Producer", "Producer", &info.Producer},
+		{"xmp:CreateDate", "CreationDate", &info.CreationDate},
+		{"xmp:ModifyDate", "ModDate", &info.ModDate},
+	}
+	for _, f := range fields {
+		value := info.XMPProperties[f.prop]
+		if value == "" {
+			continue
+		}
+		key := xmpStandardKeys[f.prop]
+		isDate := key == "creationdate" || key == "moddate"
+		if *f.target == "" || (!isDate && !metadataValuesAgree(key, *f.target, value)) {
+			*f.target = value
+			info.XMPFields = append(info.XMPFields, f.name)
+		}
+	}
+}
+
+// parseXMPProperties flattens the simple properties of an XMP packet into a map keyed by
+// "prefix:Name" (e.g. "dc:title"). Array values (rdf:Alt, rdf:Seq, rdf:Bag) are joined with "; ".
+func parseXMPProperties(packet string) map[string]string {
+	if packet == "" {
+		return nil
+	}
+	props := make(map[string]string)
+
+	prefixes := make(map[string]string)
+	for uri, prefix := range knownXMPNamespaces {
+		prefixes[uri] = prefix
+	}
+	keyFor := func(name xml.Name) string {
+		if prefix, ok := prefixes[name.Space]; ok {
+			return prefix + ":" + name.Local
+		}
+		return name.Local
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader([]byte(packet)))
+	decoder.Strict = false
+
+	var (
+		current string // property being read, if any
+		depth   int    // element depth relative to the property element
+		text    strings.Builder
+		items   []string // rdf:li values of an array property
+		inItem  bool
+	)
+
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			break
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" {
+					if _, ok := prefixes[attr.Value]; !ok {
+						prefixes[attr.Value] = attr.Name.Local
+					}
+				}
+			}
+
+			if current != "" {
+				depth++
+				if t.Name.Space == rdfNamespace && t.Name.Local == "li" {
+					inItem = true
+					text.Reset()
+				}
+				continue
+			}
+
+			if t.Name.Space == rdfNamespace && t.Name.Local == "Description" {
+				// Properties may also be written as attributes of rdf:Description
+				for _, attr := range t.Attr {
+					if attr.Name.Space == "xmlns" || attr.Name.Space == rdfNamespace || attr.Name.Space == "" {
+						continue
+					}
+					props[keyFor(attr.Name)] = strings.TrimSpace(attr.Value)
+				}
+				continue
+			}
+
+			if t.Name.Space != rdfNamespace && t.Name.Space != "" && t.Name.Space != "adobe:ns:meta/" {
+				current = keyFor(t.Name)
+				depth = 0
+				text.Reset()
+				items = nil
+			}
+
+		case xml.CharData:
+			if current != "" {
+				text.Write(t)
+			}
+
+		case xml.EndElement:
+			if current == "" {
+				continue
+			}
+			if depth > 0 {
+				if inItem && t.Name.Space == rdfNamespace && t.Name.Local == "li" {
+					if value := strings.TrimSpace(text.String()); value != "" {
+						items = append(items, value)
+					}
+					inItem = false
+					text.Reset()
+				}
+				depth--
+				continue
+			}
+
+			value := strings.TrimSpace(text.String())
+			if len(items) > 0 {
+				value = strings.Join(items, "; ")
+			}
+			if value != "" {
+				props[current] = value
+			}
+			current = ""
+		}
+	}
+
+	return props
+}